@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/acl"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func registerACLCommands(root *cobra.Command) error {
+
+	aclRoot := &cobra.Command{
+		Use:   "acl",
+		Short: "Manage Fastly ACLs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	var localFile, aclName, service, output string
+	var plan bool
+
+	syncCommand := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync a local file of IP addresses and CIDR ranges with a Fastly ACL.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot create fastly client")
+			}
+
+			// read the file up front so we don't leak the handle
+			b, err := os.ReadFile(localFile) // nolint : gosec 'localFile' is passed in via the user
+
+			if err != nil {
+				return errors.Wrap(err, "error opening local file")
+			}
+
+			serviceID, aclID, err := getRemoteACL(client, service, aclName)
+
+			if err != nil {
+				return err
+			}
+
+			m := acl.Manager(client,
+				acl.WithLocalReader(acl.NewReader(bytes.NewReader(b))),
+				acl.WithRemoteACL(serviceID, aclID),
+			)
+
+			changes, err := m.Plan()
+
+			if err != nil {
+				return err
+			}
+
+			if plan {
+				return printACLPlan(os.Stdout, changes, output)
+			}
+
+			result, err := m.Apply(changes)
+
+			// record the batches applied before any failure, nothing was written if there were none
+			if err != nil || result.Batches > 0 {
+
+				record := audit.Record{Action: audit.ACLSync, ServiceID: serviceID, Resource: aclID, Counts: map[string]int{
+					"created": result.Created,
+					"updated": result.Updated,
+					"deleted": result.Deleted,
+					"batches": result.Batches,
+				}}
+
+				if err := audit.Outcome(auditLog, record, err); err != nil {
+					return err
+				}
+			}
+
+			return printACLResult(os.Stdout, result, output)
+		},
+	}
+
+	syncCommand.Flags().StringVar(&localFile, "path", localFile, "path to file")
+	syncCommand.Flags().StringVar(&aclName, "acl", aclName, "name of ACL to update")
+	syncCommand.Flags().StringVar(&service, "service", service, "name of service to update")
+	syncCommand.Flags().BoolVar(&plan, "plan", false, "print the changes that would be made without making them")
+	syncCommand.Flags().StringVar(&output, "output", "table", "output format (table, json)")
+
+	err := markFlagsRequired(syncCommand, "path", "acl", "service")
+
+	if err != nil {
+		return err
+	}
+
+	aclRoot.AddCommand(syncCommand)
+
+	root.AddCommand(aclRoot)
+	return nil
+}
+
+// getRemoteACL resolves the service and ACL names to their IDs
+// using the active version of the service
+func getRemoteACL(client *fastly.Client, serviceName, aclName string) (string, string, error) {
+
+	services, err := client.ListServices(&fastly.ListServicesInput{})
+
+	if err != nil {
+		return "", "", errors.Wrap(err, "error searching fastly for services")
+	}
+
+	version := 0
+	serviceID := ""
+	for _, s := range services {
+		if s.Name == serviceName {
+			version = int(s.ActiveVersion)
+			serviceID = s.ID
+		}
+	}
+
+	if version == 0 {
+		return "", "", fmt.Errorf("cannot find service : %s", serviceName)
+	}
+
+	aclInstance, err := client.GetACL(&fastly.GetACLInput{
+		Service: serviceID,
+		Version: version,
+		Name:    aclName,
+	})
+
+	if err != nil {
+		return "", "", errors.Wrap(err, "error getting ACL ID")
+	}
+
+	return serviceID, aclInstance.ID, nil
+}
+
+// printACLPlan writes the changes in the requested format
+func printACLPlan(w io.Writer, changes []acl.Change, format string) error {
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	case "table":
+		if len(changes) == 0 {
+			fmt.Fprintln(w, "no changes")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "OPERATION\tENTRY\tCOMMENT")
+
+		for _, c := range changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Operation, c.Entry, c.Comment)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
+
+// printACLResult writes the result of a sync in the requested format
+func printACLResult(w io.Writer, result *acl.Result, format string) error {
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "table":
+		_, err := fmt.Fprintf(w, "%d created, %d updated, %d deleted in %d batches\n",
+			result.Created, result.Updated, result.Deleted, result.Batches)
+		return err
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mdevilliers/fastly-cli/pkg/acl"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PrintACLResult(t *testing.T) {
+	testCases := []struct {
+		format   string
+		expected string
+		err      bool
+	}{
+		{format: "table", expected: "1 created, 0 updated, 2 deleted in 1 batches\n"},
+		{format: "json", expected: "{\n  \"created\": 1,\n  \"updated\": 0,\n  \"deleted\": 2,\n  \"batches\": 1\n}\n"},
+		{format: "yaml", err: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.format, func(t *testing.T) {
+
+			w := &bytes.Buffer{}
+			err := printACLResult(w, &acl.Result{Created: 1, Deleted: 2, Batches: 1}, tc.format)
+
+			if tc.err {
+				require.NotNil(t, err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, w.String())
+		})
+	}
+}
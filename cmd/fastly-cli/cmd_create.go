@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/mdevilliers/fastly-cli/pkg/tokens"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -32,9 +33,11 @@ func registerCreateCommand(root *cobra.Command) error {
 			})
 
 			if err != nil {
-				return errors.Wrap(err, "error creating Service")
+				return audit.Outcome(auditLog, audit.Record{Action: audit.ServiceCreate, Resource: serviceName}, errors.Wrap(err, "error creating Service"))
 			}
 
+			auditWritten(audit.Record{Action: audit.ServiceCreate, ServiceID: service.ID, Resource: serviceName})
+
 			// TODO : output to different formats
 			fmt.Println("service created")
 			fmt.Println("service ID : ", service.ID)
@@ -64,10 +67,14 @@ func registerCreateCommand(root *cobra.Command) error {
 			tokenManager := tokens.Manager(client)
 			token, err := tokenManager.AddToken(tokenInput)
 
+			record := audit.Record{Action: audit.TokenAdd, ServiceID: service.ID, Resource: tokenName}
+
 			if err != nil {
-				return errors.Wrap(err, "error creating token")
+				return audit.Outcome(auditLog, record, errors.Wrap(err, "error creating token"))
 			}
 
+			auditWritten(record)
+
 			fmt.Println("API :", token.Name)
 			fmt.Println("API access token", token.AccessToken)
 
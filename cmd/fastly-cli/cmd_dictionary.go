@@ -0,0 +1,546 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/builder"
+	"github.com/mdevilliers/fastly-cli/pkg/dictionary"
+	"github.com/mdevilliers/fastly-cli/pkg/terminal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func registerDictionaryCommands(root *cobra.Command) error {
+
+	dictionaryRoot := &cobra.Command{
+		Use:   "dictionary",
+		Short: "Manage Fastly edge dictionaries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	var localFile, filetype, dict, service string
+
+	pullCommand := &cobra.Command{
+		Use:   "pull",
+		Short: "Export a Fastly edge dictionary to a local file.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot create fastly client")
+			}
+
+			ft := dictionary.CSV
+
+			if filetype != "" {
+				ft, err = dictionary.ParseFileType(filetype)
+			} else if localFile != "" {
+				ft, err = dictionary.FileTypeFromPath(localFile)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			remote, err := getRemoteDictionary(client, service, dict)
+
+			if err != nil {
+				return err
+			}
+
+			items, err := client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+				Service:    remote.ServiceID,
+				Dictionary: remote.DictionaryID,
+			})
+
+			if err != nil {
+				return errors.Wrap(err, "error retrieving dictionary items")
+			}
+
+			buf := &bytes.Buffer{}
+
+			if err := dictionary.Export(buf, ft, dictionary.WithoutLock(items)); err != nil {
+				return errors.Wrap(err, "error exporting dictionary items")
+			}
+
+			if localFile == "" {
+				_, err = buf.WriteTo(os.Stdout)
+				return err
+			}
+
+			return os.WriteFile(localFile, buf.Bytes(), 0644) // nolint : gosec 'localFile' path is passed in via the user
+		},
+	}
+
+	pullCommand.Flags().StringVar(&localFile, "path", localFile, "path to write the file to. Defaults to stdout if not supplied")
+	pullCommand.Flags().StringVar(&filetype, "file-type", filetype, "type of file (csv, json, yaml). Detected from the file extension if not supplied")
+	pullCommand.Flags().StringVar(&dict, "dict", dict, "name of dictionary to export")
+	pullCommand.Flags().StringVar(&service, "service", service, "name of service to export from")
+
+	err := markFlagsRequired(pullCommand, "dict", "service")
+
+	if err != nil {
+		return err
+	}
+
+	var from, to string
+	var ignore []string
+	var output string
+
+	diffCommand := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare two Fastly edge dictionaries.",
+		Long: `Compare two Fastly edge dictionaries printing the keys added, removed and changed.
+
+Dictionaries are specified as service/dictionary or service/dictionary@version.
+If the version is not supplied the active version of the service is used.
+
+Dictionary items are not versioned. The version only selects which dictionary
+the name refers to, so two versions of a service sharing a dictionary cannot be
+compared.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot create fastly client")
+			}
+
+			fromDict, err := resolveDictionaryRef(client, from)
+
+			if err != nil {
+				return err
+			}
+
+			toDict, err := resolveDictionaryRef(client, to)
+
+			if err != nil {
+				return err
+			}
+
+			if err := distinctDictionaries(from, to, fromDict, toDict); err != nil {
+				return err
+			}
+
+			fromItems, err := listDictionaryItems(client, fromDict, from)
+
+			if err != nil {
+				return err
+			}
+
+			toItems, err := listDictionaryItems(client, toDict, to)
+
+			if err != nil {
+				return err
+			}
+
+			changes, err := dictionary.Compare(fromItems, toItems, ignore...)
+
+			if err != nil {
+				return err
+			}
+
+			return printDiff(os.Stdout, changes, output)
+		},
+	}
+
+	diffCommand.Flags().StringVar(&from, "from", from, "dictionary to compare from (service/dictionary[@version])")
+	diffCommand.Flags().StringVar(&to, "to", to, "dictionary to compare to (service/dictionary[@version])")
+	diffCommand.Flags().StringSliceVar(&ignore, "ignore", ignore, "keys, or glob patterns of keys in which * also matches /, that are expected to differ")
+	diffCommand.Flags().StringVar(&output, "output", "table", "output format (table, json)")
+
+	err = markFlagsRequired(diffCommand, "from", "to")
+
+	if err != nil {
+		return err
+	}
+
+	var promoteFrom, promoteTo string
+	var yes bool
+	var flags syncFlags
+
+	promoteCommand := &cobra.Command{
+		Use:   "promote",
+		Short: "Copy the items of one Fastly edge dictionary to another.",
+		Long: `Copy the items of one Fastly edge dictionary to another e.g. from staging to production.
+
+The source is specified as service/dictionary or service/dictionary@version and the
+target as service/dictionary. The changes are shown and confirmed before they are made.
+
+Dictionary items are not versioned. The version only selects which dictionary
+the name refers to, not the items it held at that version.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot create fastly client")
+			}
+
+			return promote(client, promoteFrom, promoteTo, yes, flags)
+		},
+	}
+
+	promoteCommand.Flags().StringVar(&promoteFrom, "from", promoteFrom, "dictionary to copy from (service/dictionary[@version])")
+	promoteCommand.Flags().StringVar(&promoteTo, "to", promoteTo, "dictionary to copy to (service/dictionary)")
+	promoteCommand.Flags().StringSliceVar(&flags.include, "include-prefix", flags.include, "only copy keys starting with one of the prefixes")
+	promoteCommand.Flags().StringSliceVar(&flags.exclude, "exclude-prefix", flags.exclude, "do not copy keys starting with any of the prefixes")
+	promoteCommand.Flags().BoolVar(&yes, "yes", false, "make the changes without asking for confirmation")
+	promoteCommand.Flags().StringVar(&flags.output, "output", "table", "output format (table, json)")
+	promoteCommand.Flags().StringVar(&flags.strategy, "strategy", string(dictionary.Mirror), "which changes to make (mirror, upsert-only, create-only)")
+	promoteCommand.Flags().StringVar(&flags.maxDeletions, "max-deletions", flags.maxDeletions, "maximum number (e.g. 10) or percentage (e.g. 5%) of target items that can be deleted")
+	promoteCommand.Flags().BoolVar(&flags.force, "force", false, "ignore --max-deletions")
+	promoteCommand.Flags().BoolVar(&flags.preferLocal, "prefer-local", false, "overwrite items changed in both the target and the source since the last sync with the source items")
+	promoteCommand.Flags().BoolVar(&flags.preferRemote, "prefer-remote", false, "keep items changed in both the target and the source since the last sync, skipping the source changes")
+	promoteCommand.MarkFlagsMutuallyExclusive("prefer-local", "prefer-remote")
+
+	err = markFlagsRequired(promoteCommand, "from", "to")
+
+	if err != nil {
+		return err
+	}
+
+	lintCommand, err := dictionaryLintCommand()
+
+	if err != nil {
+		return err
+	}
+
+	dictionaryRoot.AddCommand(pullCommand)
+	dictionaryRoot.AddCommand(diffCommand)
+	dictionaryRoot.AddCommand(promoteCommand)
+	dictionaryRoot.AddCommand(lintCommand)
+
+	root.AddCommand(dictionaryRoot)
+	return nil
+}
+
+// dictionaryRef is a user supplied reference to a dictionary
+// of the form service/dictionary[@version]
+type dictionaryRef struct {
+	Service    string
+	Dictionary string
+	Version    int
+}
+
+func parseDictionaryRef(str string) (dictionaryRef, error) {
+
+	ref := dictionaryRef{}
+
+	if i := strings.LastIndex(str, "@"); i > -1 {
+
+		version, err := strconv.Atoi(str[i+1:])
+
+		if err != nil || version < 1 {
+			return ref, fmt.Errorf("invalid version in %s", str)
+		}
+
+		ref.Version = version
+		str = str[:i]
+	}
+
+	i := strings.LastIndex(str, "/")
+
+	if i < 1 || i == len(str)-1 {
+		return ref, fmt.Errorf("invalid dictionary %s : expected service/dictionary", str)
+	}
+
+	ref.Service = str[:i]
+	ref.Dictionary = str[i+1:]
+
+	return ref, nil
+}
+
+// resolveDictionaryRef resolves the referenced dictionary to its IDs
+func resolveDictionaryRef(client *fastly.Client, str string) (remoteDictionary, error) {
+
+	ref, err := parseDictionaryRef(str)
+
+	if err != nil {
+		return remoteDictionary{}, err
+	}
+
+	return getRemoteDictionaryAtVersion(client, ref.Service, ref.Dictionary, ref.Version)
+}
+
+// distinctDictionaries returns an error if the references resolved to the same dictionary.
+// Dictionary items are not versioned so two versions of a service can share a dictionary
+// and comparing them would always find no differences.
+func distinctDictionaries(from, to string, fromDict, toDict remoteDictionary) error {
+
+	if fromDict.DictionaryID != toDict.DictionaryID {
+		return nil
+	}
+
+	if fromDict.Version != toDict.Version {
+		return fmt.Errorf("%s and %s are the same dictionary : dictionary items are not versioned, the version only selects the dictionary", from, to)
+	}
+
+	return fmt.Errorf("%s and %s are the same dictionary", from, to)
+}
+
+// listDictionaryItemsByRef returns all of the items in the referenced dictionary
+func listDictionaryItemsByRef(client *fastly.Client, str string) ([]*fastly.DictionaryItem, error) {
+
+	remote, err := resolveDictionaryRef(client, str)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return listDictionaryItems(client, remote, str)
+}
+
+// listDictionaryItems returns all of the items in the remote dictionary
+func listDictionaryItems(client *fastly.Client, remote remoteDictionary, str string) ([]*fastly.DictionaryItem, error) {
+
+	items, err := client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+		Service:    remote.ServiceID,
+		Dictionary: remote.DictionaryID,
+	})
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving dictionary items for %s", str)
+	}
+
+	return dictionary.WithoutLock(items), nil
+}
+
+// promote syncs the target dictionary with the items of the source dictionary
+// after the planned changes have been confirmed
+func promote(client *fastly.Client, from, to string, yes bool, flags syncFlags) error {
+
+	fromRef, err := parseDictionaryRef(from)
+
+	if err != nil {
+		return err
+	}
+
+	toRef, err := parseDictionaryRef(to)
+
+	if err != nil {
+		return err
+	}
+
+	// items are written to the dictionary whatever the version
+	if toRef.Version != 0 {
+		return fmt.Errorf("invalid dictionary %s : a version cannot be promoted to", to)
+	}
+
+	source, err := getRemoteDictionaryAtVersion(client, fromRef.Service, fromRef.Dictionary, fromRef.Version)
+
+	if err != nil {
+		return err
+	}
+
+	target, err := getRemoteDictionary(client, toRef.Service, toRef.Dictionary)
+
+	if err != nil {
+		return err
+	}
+
+	if err := distinctDictionaries(from, to, source, target); err != nil {
+		return errors.Wrap(err, "cannot promote a dictionary to itself")
+	}
+
+	syncer, err := flags.newSyncer(client, dictionary.NewRemoteReader(client, source.ServiceID, source.DictionaryID), target.ServiceID, target.DictionaryID)
+
+	if err != nil {
+		return err
+	}
+
+	changes, err := syncer.Plan()
+
+	if err != nil {
+		return err
+	}
+
+	if err := printPlan(os.Stdout, changes, flags.output); err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if !yes {
+
+		confirmed, err := terminal.Confirm(fmt.Sprintf("Make %d changes to %s", len(changes), to))
+
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			return errors.New("promotion cancelled")
+		}
+	}
+
+	result, err := syncer.Apply(changes)
+
+	if err != nil {
+		return err
+	}
+
+	return printResult(os.Stdout, result, flags.output)
+}
+
+// printDiff writes the differences between two dictionaries in the requested format
+func printDiff(w io.Writer, changes []dictionary.Change, format string) error {
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	case "table":
+		if len(changes) == 0 {
+			fmt.Fprintln(w, "no differences")
+			return nil
+		}
+
+		labels := map[fastly.BatchOperation]string{
+			fastly.CreateBatchOperation: "added",
+			fastly.DeleteBatchOperation: "removed",
+			fastly.UpdateBatchOperation: "changed",
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DIFFERENCE\tKEY\tFROM\tTO")
+
+		for _, c := range changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", labels[c.Operation], c.Key, c.From, c.To)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
+
+// errDictionaryNotFound signals the service exists but the dictionary does not
+var errDictionaryNotFound = errors.New("dictionary not found")
+
+// remoteDictionary contains the IDs required to address a Fastly edge dictionary
+type remoteDictionary struct {
+	ServiceID    string
+	Version      int
+	DictionaryID string
+}
+
+// getRemoteDictionary resolves the service and dictionary names to their IDs
+// using the active version of the service
+func getRemoteDictionary(client *fastly.Client, serviceName, dictName string) (remoteDictionary, error) {
+	return getRemoteDictionaryAtVersion(client, serviceName, dictName, 0)
+}
+
+// getRemoteDictionaryAtVersion resolves the service and dictionary names to their IDs
+// using the version of the service or the active version if version is 0
+func getRemoteDictionaryAtVersion(client *fastly.Client, serviceName, dictName string, version int) (remoteDictionary, error) {
+
+	services, err := client.ListServices(&fastly.ListServicesInput{})
+
+	if err != nil {
+		return remoteDictionary{}, errors.Wrap(err, "error searching fastly for services")
+	}
+
+	activeVersion := 0
+	serviceID := ""
+	for _, s := range services {
+		if s.Name == serviceName {
+			activeVersion = int(s.ActiveVersion)
+			serviceID = s.ID
+		}
+	}
+
+	if activeVersion == 0 {
+		return remoteDictionary{}, fmt.Errorf("cannot find service : %s", serviceName)
+	}
+
+	if version == 0 {
+		version = activeVersion
+	}
+
+	remote := remoteDictionary{
+		ServiceID: serviceID,
+		Version:   version,
+	}
+
+	dictInstance, err := client.GetDictionary(&fastly.GetDictionaryInput{
+		Service: serviceID,
+		Version: version,
+		Name:    dictName,
+	})
+
+	if err != nil {
+
+		httpError, ok := err.(*fastly.HTTPError) // nolint: errorlint
+		if ok && httpError.IsNotFound() {
+			return remote, errors.Wrap(errDictionaryNotFound, dictName)
+		}
+
+		return remoteDictionary{}, errors.Wrap(err, "error getting dictionary ID")
+	}
+
+	remote.DictionaryID = dictInstance.ID
+	return remote, nil
+}
+
+// createDictionaries clones the version of the service, creates the named dictionaries
+// and activates the new version. The new dictionary IDs are returned keyed by name.
+func createDictionaries(client *fastly.Client, serviceID string, version int, names ...string) (map[string]string, error) {
+
+	ids := map[string]string{}
+
+	create := func(current builder.ServiceInfo) error {
+
+		for _, name := range names {
+
+			d, err := client.CreateDictionary(&fastly.CreateDictionaryInput{
+				Service: current.ID,
+				Version: current.Version,
+				Name:    name,
+			})
+
+			if err != nil {
+				return errors.Wrapf(err, "error creating dictionary %s", name)
+			}
+
+			ids[name] = d.ID
+		}
+		return nil
+	}
+
+	err := builder.New(client, serviceID, version, builder.WithAudit(auditLog)).Apply(create)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// dictionaryClient is the subset of the fastly.Client used to sync a dictionary
+type dictionaryClient interface {
+	ListDictionaryItems(*fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error)
+	BatchModifyDictionaryItems(*fastly.BatchModifyDictionaryItemsInput) error
+}
+
+// missingDictionary stands in for a dictionary that has not been created yet
+// so that a plan can be made against it without creating it
+type missingDictionary struct {
+	*fastly.Client
+}
+
+func (missingDictionary) ListDictionaryItems(*fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+	return nil, nil
+}
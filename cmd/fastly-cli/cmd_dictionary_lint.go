@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/dictionary"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// lintRedirects is the kind of lint checking dictionaries of paths to redirect URLs
+const lintRedirects = "redirects"
+
+// redirectOptions returns the options for linting redirects if kind is redirects,
+// nil if kind is empty, or an error for any other kind
+func redirectOptions(kind string, maxHops int, hosts []string) (*dictionary.RedirectOptions, error) {
+
+	switch kind {
+	case "":
+		return nil, nil
+	case lintRedirects:
+		return &dictionary.RedirectOptions{MaxHops: maxHops, Hosts: hosts}, nil
+	}
+	return nil, fmt.Errorf("unsupported lint kind : %s", kind)
+}
+
+func dictionaryLintCommand() (*cobra.Command, error) {
+
+	var localFiles, hosts []string
+	var filetype, remote, kind, output string
+	var maxHops int
+	var csv dictionary.CSVDialect
+
+	lintCommand := &cobra.Command{
+		Use:   "lint",
+		Short: "Check a local file or Fastly edge dictionary for problems.",
+		Long: `Check a local file or Fastly edge dictionary for problems.
+
+The redirects kind checks dictionaries mapping paths to redirect URLs for cycles, chains
+longer than --max-hops, self-redirects, keys that are not normalized paths and values that
+are not valid URLs. Relative URLs are followed to the next redirect as are absolute URLs to
+one of the --redirect-host hosts.
+
+The remote dictionary is specified as service/dictionary or service/dictionary@version.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			options, err := redirectOptions(kind, maxHops, hosts)
+
+			if err != nil {
+				return err
+			}
+
+			// an empty kind disables linting when syncing but there is nothing to check here
+			if options == nil {
+				return fmt.Errorf("unsupported lint kind : %q", kind)
+			}
+
+			items := map[string]string{}
+
+			if remote != "" {
+
+				client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
+
+				if err != nil {
+					return errors.Wrap(err, "cannot create fastly client")
+				}
+
+				remoteItems, err := listDictionaryItemsByRef(client, remote)
+
+				if err != nil {
+					return err
+				}
+
+				for _, item := range remoteItems {
+					items[item.ItemKey] = item.ItemValue
+				}
+			} else {
+
+				reader, err := openLocalFiles(localFiles, filetype, csv)
+
+				if err != nil {
+					return err
+				}
+
+				records, err := reader.ReadAll()
+
+				if err != nil {
+					return errors.Wrap(err, "error reading local dictionary items")
+				}
+
+				for _, r := range records {
+					items[r[0]] = r[1]
+				}
+			}
+
+			problems := dictionary.LintRedirects(items, *options)
+
+			if err := printRedirectProblems(os.Stdout, problems, output); err != nil {
+				return err
+			}
+
+			if len(problems) > 0 {
+				return fmt.Errorf("%d redirect problems found", len(problems))
+			}
+			return nil
+		},
+	}
+
+	lintCommand.Flags().StringVar(&kind, "kind", kind, "kind of dictionary to check (redirects)")
+	lintCommand.Flags().StringArrayVar(&localFiles, "path", localFiles, "path to a local file. Repeat to merge layers in order")
+	lintCommand.Flags().StringVar(&filetype, "file-type", filetype, "type of file (csv, json, yaml, env). Detected from the file extension if not supplied")
+	lintCommand.Flags().StringVar(&csv.Delimiter, "csv-delimiter", csv.Delimiter, `delimiter between CSV columns e.g. ";" or "tab". Defaults to ","`)
+	lintCommand.Flags().StringVar(&csv.Comment, "csv-comment", csv.Comment, `character starting CSV lines to ignore e.g. "#"`)
+	lintCommand.Flags().BoolVar(&csv.Header, "csv-header", false, "skip the first row of the CSV file")
+	lintCommand.Flags().StringVar(&csv.KeyColumn, "csv-key-column", csv.KeyColumn, "name of the CSV header column holding the keys. Requires --csv-value-column")
+	lintCommand.Flags().StringVar(&csv.ValueColumn, "csv-value-column", csv.ValueColumn, "name of the CSV header column holding the values. Requires --csv-key-column")
+	lintCommand.Flags().BoolVar(&csv.Strict, "csv-strict", false, "report CSV rows with more than a key and value column as malformed rather than ignoring the other columns")
+	lintCommand.Flags().StringVar(&remote, "remote", remote, "dictionary to check (service/dictionary[@version])")
+	lintCommand.Flags().IntVar(&maxHops, "max-hops", 3, "longest chain of redirects allowed")
+	lintCommand.Flags().StringSliceVar(&hosts, "redirect-host", hosts, "host served by the redirects whose absolute URLs are followed")
+	lintCommand.Flags().StringVar(&output, "output", "table", "output format (table, json)")
+
+	lintCommand.MarkFlagsOneRequired("path", "remote")
+	lintCommand.MarkFlagsMutuallyExclusive("path", "remote")
+	lintCommand.MarkFlagsRequiredTogether("csv-key-column", "csv-value-column")
+
+	return lintCommand, markFlagsRequired(lintCommand, "kind")
+}
+
+// printRedirectProblems writes the problems in the requested format
+func printRedirectProblems(w io.Writer, problems []dictionary.RedirectProblem, format string) error {
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(problems)
+	case "table":
+		if len(problems) == 0 {
+			fmt.Fprintln(w, "no problems")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tPROBLEM\tDETAIL")
+
+		for _, p := range problems {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Key, p.Kind, p.Detail)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
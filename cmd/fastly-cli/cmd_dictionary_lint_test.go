@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DictionaryLintRejectsKind(t *testing.T) {
+	testCases := []struct {
+		name string
+		kind string
+	}{
+		{name: "empty", kind: ""},
+		{name: "unknown", kind: "cycles"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			cmd, err := dictionaryLintCommand()
+			require.Nil(t, err)
+
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			// the kind is rejected before the missing file is read
+			cmd.SetArgs([]string{"--kind=" + tc.kind, "--path", filepath.Join(t.TempDir(), "missing.csv")})
+
+			err = cmd.Execute()
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "unsupported lint kind")
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DistinctDictionaries(t *testing.T) {
+	testCases := []struct {
+		name  string
+		from  remoteDictionary
+		to    remoteDictionary
+		valid bool
+	}{
+		{
+			name:  "different dictionaries",
+			from:  remoteDictionary{ServiceID: "staging", Version: 3, DictionaryID: "one"},
+			to:    remoteDictionary{ServiceID: "production", Version: 3, DictionaryID: "two"},
+			valid: true,
+		},
+		{
+			name:  "dictionary recreated in a later version",
+			from:  remoteDictionary{ServiceID: "service", Version: 3, DictionaryID: "one"},
+			to:    remoteDictionary{ServiceID: "service", Version: 5, DictionaryID: "two"},
+			valid: true,
+		},
+		{
+			name: "versions sharing a dictionary",
+			from: remoteDictionary{ServiceID: "service", Version: 3, DictionaryID: "one"},
+			to:   remoteDictionary{ServiceID: "service", Version: 5, DictionaryID: "one"},
+		},
+		{
+			name: "same dictionary",
+			from: remoteDictionary{ServiceID: "service", Version: 5, DictionaryID: "one"},
+			to:   remoteDictionary{ServiceID: "service", Version: 5, DictionaryID: "one"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			err := distinctDictionaries("from", "to", tc.from, tc.to)
+
+			if tc.valid {
+				require.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+			}
+		})
+	}
+}
@@ -53,6 +53,7 @@ func registerEavesdropCommand(root *cobra.Command) error {
 				service,
 				eavesdrop.WithExternalBinding(externalEndpoint, externalPort),
 				eavesdrop.WithLocalBinding(localEndpoint, localPort),
+				eavesdrop.WithAudit(auditLog),
 			)
 
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
+	"github.com/spf13/cobra"
+)
+
+func registerHistoryCommand(root *cobra.Command) error {
+
+	var query audit.Query
+	var since time.Duration
+	var output string
+
+	historyCommand := &cobra.Command{
+		Use:   "history",
+		Short: "Show the writes made to Fastly from this machine.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if since > 0 {
+				query.Since = time.Now().Add(-since)
+			}
+
+			records, err := audit.Read(filepath.Join(stateDir(), "audit.jsonl"), query)
+
+			if err != nil {
+				return err
+			}
+
+			return printHistory(os.Stdout, records, output)
+		},
+	}
+
+	historyCommand.Flags().StringVar(&query.ServiceID, "service-id", query.ServiceID, "only show writes to the service")
+	historyCommand.Flags().StringVar(&query.Action, "action", query.Action, "only show writes of the action e.g. dictionary.sync")
+	historyCommand.Flags().DurationVar(&since, "since", since, "only show writes made within the duration e.g. 24h")
+	historyCommand.Flags().IntVar(&query.Limit, "limit", 20, "maximum number of the most recent writes to show. 0 shows all")
+	historyCommand.Flags().StringVar(&output, "output", "table", "output format (table, json)")
+
+	root.AddCommand(historyCommand)
+	return nil
+}
+
+// printHistory writes the audit records in the requested format
+func printHistory(w io.Writer, records []audit.Record, format string) error {
+
+	switch format {
+	case "json":
+		// one record per line as in the audit log
+		encoder := json.NewEncoder(w)
+		for _, r := range records {
+			if err := encoder.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TIME\tUSER\tCOMMAND\tACTION\tSERVICE\tRESOURCE\tVERSION\tCOUNTS\tERROR")
+
+		for _, r := range records {
+
+			version := ""
+			if r.ToVersion != 0 {
+				version = fmt.Sprintf("%d -> %d", r.FromVersion, r.ToVersion)
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Time.Local().Format(time.RFC3339), r.User, r.Command, r.Action,
+				r.ServiceID, r.Resource, version, formatCounts(r.Counts), r.Error)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
+
+// formatCounts returns the counts sorted by name e.g. created=1 deleted=2
+func formatCounts(counts map[string]int) string {
+
+	names := make([]string, 0, len(counts))
+
+	for name := range counts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	formatted := make([]string, 0, len(names))
+
+	for _, name := range names {
+		formatted = append(formatted, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+
+	return strings.Join(formatted, " ")
+}
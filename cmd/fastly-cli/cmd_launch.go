@@ -86,14 +86,22 @@ func fuzzyMatch(services []*fastly.Service, term string) []*fastly.Service {
 		return services
 	}
 
-	results := fuzzy.FindFrom(term, servicesSource(services))
+	matches := fuzzyFind(services, term)
 
 	// no fuzzy matches so return all sorted by name
-	if len(results) == 0 {
+	if len(matches) == 0 {
 		sort.Sort(byName(services))
 		return services
 	}
 
+	return matches
+}
+
+// fuzzyFind returns only the services matching the term ordered by how well they match
+func fuzzyFind(services []*fastly.Service, term string) []*fastly.Service {
+
+	results := fuzzy.FindFrom(term, servicesSource(services))
+
 	ordered := []*fastly.Service{}
 	for _, r := range results {
 		ordered = append(ordered, services[r.Index])
@@ -1,10 +1,16 @@
 package main
 
 import (
-	"bufio"
-	"encoding/csv"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/fastly/go-fastly/fastly"
 	"github.com/mdevilliers/fastly-cli/pkg/dictionary"
@@ -12,76 +18,969 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// syncFlags are the options shared by each way of running sync
+type syncFlags struct {
+	plan          bool
+	createMissing bool
+	force         bool
+	resume        bool
+	revert        bool
+	journal       string
+	substitute    bool
+	preferLocal   bool
+	preferRemote  bool
+	watch         bool
+	debounce      time.Duration
+	output        string
+	strategy      string
+	maxDeletions  string
+	rules         string
+	include       []string
+	exclude       []string
+	parallelism   int
+	csv           dictionary.CSVDialect
+	lint          string
+	scope         string
+	lock          bool
+	lockTTL       time.Duration
+	lockWait      time.Duration
+	out           string
+	maxHops       int
+	hosts         []string
+}
+
+// dictionarySyncer plans and applies changes to a single remote dictionary
+type dictionarySyncer interface {
+	Check() error
+	Plan() ([]dictionary.Change, error)
+	Apply(changes []dictionary.Change) (*dictionary.Result, error)
+	Resume() error
+	Revert() error
+	SavePlan(changes []dictionary.Change) (*dictionary.SavedPlan, error)
+	ApplyPlan(p *dictionary.SavedPlan) (*dictionary.Result, error)
+}
+
+// checkLocal validates the local files against the scope, rules and redirect lint of
+// the flags before the remote dictionary is looked up or created. The files are read
+// again, and checked again, when the changes are planned.
+func (f syncFlags) checkLocal(paths []string, fileType string, dialect dictionary.CSVDialect) error {
+
+	reader, err := openLocalFiles(paths, fileType, dialect)
+
+	if err != nil {
+		return err
+	}
+
+	syncer, err := f.newSyncer(nil, reader, "", "")
+
+	if err != nil {
+		return err
+	}
+
+	return syncer.Check()
+}
+
+// forDictionary returns the flags with the rules, lint and scope of a manifest dictionary
+func (f syncFlags) forDictionary(md dictionary.ManifestDictionary) syncFlags {
+	f.rules = md.Rules
+	f.lint = md.Lint
+	f.scope = md.Scope
+	return f
+}
+
+// newSyncer returns a dictionary.Manager configured from the flags
+func (f syncFlags) newSyncer(client dictionaryClient, reader localDictionaryReader, serviceID, dictionaryID string) (dictionarySyncer, error) {
+
+	strategy, err := dictionary.ParseStrategy(f.strategy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var maxDeletions *dictionary.DeletionLimit
+
+	if f.maxDeletions != "" && !f.force {
+
+		limit, err := dictionary.ParseDeletionLimit(f.maxDeletions)
+
+		if err != nil {
+			return nil, err
+		}
+		maxDeletions = &limit
+	}
+
+	journal := f.journal
+
+	if journal == "" {
+		journal = filepath.Join(stateDir(), "journals", fmt.Sprintf("%s-%s.json", serviceID, dictionaryID))
+	}
+
+	var lookupEnv func(string) (string, bool)
+
+	if f.substitute {
+		lookupEnv = os.LookupEnv
+	}
+
+	var rules *dictionary.Rules
+
+	if f.rules != "" {
+		rules, err = dictionary.LoadRules(f.rules)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	redirects, err := redirectOptions(f.lint, f.maxHops, f.hosts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lock *dictionary.LockOptions
+
+	// a plan makes no changes so does not take the lock
+	if f.lock && !f.plan {
+		lock = &dictionary.LockOptions{Owner: lockOwner(), TTL: f.lockTTL, Wait: f.lockWait}
+	}
+
+	resolution := dictionary.Abort
+
+	if f.preferLocal {
+		resolution = dictionary.PreferLocal
+	}
+
+	if f.preferRemote {
+		resolution = dictionary.PreferRemote
+	}
+
+	return dictionary.Manager(client,
+		dictionary.WithLocalReader(reader),
+		dictionary.WithRemoteDictionary(serviceID, dictionaryID),
+		dictionary.WithStrategy(strategy),
+		dictionary.WithMaxDeletions(maxDeletions),
+		dictionary.WithJournal(journal),
+		dictionary.WithLastSync(filepath.Join(stateDir(), "last-sync", fmt.Sprintf("%s-%s.json", serviceID, dictionaryID))),
+		dictionary.WithConflictResolution(resolution),
+		dictionary.WithSubstitution(lookupEnv),
+		dictionary.WithRules(rules),
+		dictionary.WithKeyFilter(f.include, f.exclude),
+		dictionary.WithKeyScope(f.scope),
+		dictionary.WithLock(lock),
+		dictionary.WithRedirectLint(redirects),
+		dictionary.WithAudit(auditLog),
+	), nil
+}
+
 func registerSyncCommand(root *cobra.Command) error {
 
-	var localFile, filetype, dict, service string
+	var filetype, dict, manifest, serviceMatch string
+	var localFiles, services []string
+	var flags syncFlags
 
 	syncCommand := &cobra.Command{
 		Use:   "sync",
-		Short: "Sync local CSV files with Fastly edge dictionaries.",
+		Short: "Sync local files with Fastly edge dictionaries.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 
+			if dict != "" && len(services) == 0 && serviceMatch == "" {
+				return errors.New(`if the "dict" flag is set then "service" or "service-match" must be set`)
+			}
+
+			fanOut := len(services) > 1 || serviceMatch != ""
+
+			if fanOut && (flags.resume || flags.revert || flags.journal != "") {
+				return errors.New(`"resume", "revert" and "journal" require a single "service"`)
+			}
+
+			if flags.out != "" && (fanOut || manifest != "" || flags.watch) {
+				return errors.New(`"out" requires a single "service" and "dict" and cannot be used with "watch"`)
+			}
+
 			client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
 
 			if err != nil {
 				return errors.Wrap(err, "cannot create fastly client")
 			}
 
-			// we only know about csv files
-			csvFile, err := os.Open(localFile) // nolint : gosec 'localFile' path is passed in via the user
+			if flags.resume || flags.revert {
+				return resumeOrRevert(client, services[0], dict, flags)
+			}
 
-			if err != nil {
-				return errors.Wrap(err, "error opening csv file")
+			if manifest == "" && len(localFiles) == 0 {
+				return errors.New(`required flag(s) "path" not set`)
 			}
 
-			reader := csv.NewReader(bufio.NewReader(csvFile))
+			run := func() error {
 
-			services, err := client.ListServices(&fastly.ListServicesInput{})
+				if manifest != "" {
+					return syncManifest(client, manifest, flags)
+				}
+				return syncFile(client, localFiles, filetype, services[0], dict, flags)
+			}
 
-			if err != nil {
-				return errors.Wrap(err, "error searching fastly for services")
+			if fanOut {
+
+				targets, err := fanOutServices(client, services, serviceMatch, localFiles, filetype, dict, flags)
+
+				if err != nil {
+					return err
+				}
+
+				run = func() error {
+					return syncServices(client, targets, flags)
+				}
 			}
 
-			version := 0
-			serviceID := ""
-			for _, s := range services {
-				if s.Name == service {
-					version = int(s.ActiveVersion)
-					serviceID = s.ID
+			if !flags.watch {
+				return run()
+			}
+
+			paths := append([]string{}, localFiles...)
+
+			if flags.rules != "" {
+				paths = append(paths, flags.rules)
+			}
+
+			if manifest != "" {
+				paths, err = manifestPaths(manifest)
+
+				if err != nil {
+					return err
 				}
 			}
 
-			if version == 0 {
-				return fmt.Errorf("cannot find service : %s", service)
+			return watch(paths, flags.debounce, run)
+		},
+	}
+
+	syncCommand.Flags().StringArrayVar(&localFiles, "path", localFiles, "path to file. Repeat to merge layers in order, later layers replacing the items of earlier ones")
+	syncCommand.Flags().StringVar(&filetype, "file-type", filetype, "type of file (csv, json, yaml, env). Detected from the file extension if not supplied")
+	syncCommand.Flags().StringVar(&dict, "dict", dict, "name of dictionary to update")
+	syncCommand.Flags().StringSliceVar(&services, "service", services, "name of service to update. Repeat, or separate with commas, to update many services")
+	syncCommand.Flags().StringVar(&serviceMatch, "service-match", serviceMatch, "update every service fuzzy matching the term")
+	syncCommand.Flags().IntVar(&flags.parallelism, "parallelism", 4, "maximum number of services to update at once")
+	syncCommand.Flags().BoolVar(&flags.plan, "plan", false, "print the changes that would be made without making them")
+	syncCommand.Flags().StringVar(&flags.output, "output", "table", "output format (table, json)")
+	syncCommand.Flags().StringVar(&flags.strategy, "strategy", string(dictionary.Mirror), "which changes to make (mirror, upsert-only, create-only)")
+
+	syncCommand.Flags().StringVar(&flags.maxDeletions, "max-deletions", flags.maxDeletions, "maximum number (e.g. 10) or percentage (e.g. 5%) of remote items that can be deleted")
+	syncCommand.Flags().BoolVar(&flags.force, "force", false, "ignore --max-deletions")
+
+	syncCommand.Flags().BoolVar(&flags.createMissing, "create-missing", false, "create the dictionary in a new service version if it does not exist")
+	syncCommand.Flags().StringVar(&manifest, "manifest", manifest, "path to a YAML manifest of services, dictionaries and files to sync")
+
+	syncCommand.Flags().StringVar(&flags.journal, "journal", flags.journal, "path to the journal of applied batches. Defaults to a file per dictionary in the fastly-cli config directory")
+	syncCommand.Flags().BoolVar(&flags.resume, "resume", false, "apply the remaining batches of a failed sync from the journal")
+	syncCommand.Flags().BoolVar(&flags.revert, "revert", false, "restore the dictionary to its state before the last journaled sync")
+
+	syncCommand.Flags().BoolVar(&flags.substitute, "substitute", false, "expand ${ENV_VAR} and ${file:/path} placeholders in values. Substituted values are redacted in any output")
+
+	syncCommand.Flags().StringVar(&flags.rules, "rules", flags.rules, "path to a YAML file of rules constraining item values by key. Checked before any change is planned")
+
+	syncCommand.Flags().StringVar(&flags.csv.Delimiter, "csv-delimiter", flags.csv.Delimiter, `delimiter between CSV columns e.g. ";" or "tab". Defaults to ","`)
+	syncCommand.Flags().StringVar(&flags.csv.Comment, "csv-comment", flags.csv.Comment, `character starting CSV lines to ignore e.g. "#"`)
+	syncCommand.Flags().BoolVar(&flags.csv.Header, "csv-header", false, "skip the first row of the CSV file")
+	syncCommand.Flags().StringVar(&flags.csv.KeyColumn, "csv-key-column", flags.csv.KeyColumn, "name of the CSV header column holding the keys. Requires --csv-value-column")
+	syncCommand.Flags().StringVar(&flags.csv.ValueColumn, "csv-value-column", flags.csv.ValueColumn, "name of the CSV header column holding the values. Requires --csv-key-column")
+	syncCommand.Flags().BoolVar(&flags.csv.Strict, "csv-strict", false, "report CSV rows with more than a key and value column as malformed rather than ignoring the other columns")
+
+	syncCommand.Flags().StringVar(&flags.scope, "scope", flags.scope, "key prefix, or glob pattern in which * also matches /, owned by this sync. Remote keys outside the scope are left untouched and local keys outside it are rejected")
+
+	syncCommand.Flags().BoolVar(&flags.lock, "lock", false, "take a lock stored in the dictionary so concurrent syncs of it cannot interleave")
+	syncCommand.Flags().DurationVar(&flags.lockTTL, "lock-ttl", 10*time.Minute, "how long the lock is held before it expires and can be stolen")
+	syncCommand.Flags().DurationVar(&flags.lockWait, "lock-wait", 0, "how long to wait for a lock held by another sync. By default the sync fails immediately")
+
+	syncCommand.Flags().StringVar(&flags.lint, "lint", flags.lint, "check the local items before any change is planned (redirects)")
+	syncCommand.Flags().IntVar(&flags.maxHops, "max-hops", 3, "longest chain of redirects allowed when linting redirects")
+	syncCommand.Flags().StringSliceVar(&flags.hosts, "redirect-host", flags.hosts, "host served by the redirects whose absolute URLs are followed when linting redirects")
+
+	syncCommand.Flags().BoolVar(&flags.preferLocal, "prefer-local", false, "overwrite items changed both remotely and locally since the last sync with the local items")
+	syncCommand.Flags().BoolVar(&flags.preferRemote, "prefer-remote", false, "keep items changed both remotely and locally since the last sync, skipping the local changes")
+
+	syncCommand.Flags().BoolVar(&flags.watch, "watch", false, "re-sync whenever the local file, or any file in the manifest, changes")
+	syncCommand.Flags().DurationVar(&flags.debounce, "debounce", 500*time.Millisecond, "how long to wait for edits to settle before re-syncing when watching")
+
+	syncCommand.MarkFlagsMutuallyExclusive("prefer-local", "prefer-remote")
+	syncCommand.MarkFlagsMutuallyExclusive("service", "service-match", "manifest")
+	syncCommand.MarkFlagsOneRequired("dict", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("dict", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("path", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("journal", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("rules", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("lint", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("scope", "manifest")
+	syncCommand.MarkFlagsRequiredTogether("csv-key-column", "csv-value-column")
+
+	for _, f := range []string{"csv-delimiter", "csv-comment", "csv-header", "csv-key-column", "csv-value-column", "csv-strict"} {
+		syncCommand.MarkFlagsMutuallyExclusive(f, "manifest")
+	}
+	syncCommand.MarkFlagsMutuallyExclusive("resume", "revert", "plan")
+	syncCommand.MarkFlagsMutuallyExclusive("resume", "revert", "watch")
+	syncCommand.MarkFlagsMutuallyExclusive("resume", "manifest")
+	syncCommand.MarkFlagsMutuallyExclusive("revert", "manifest")
+
+	planCommand := &cobra.Command{
+		Use:   "plan",
+		Short: "Print, and save, the changes a sync would make without making them.",
+		Long: `Print, and save, the changes a sync would make without making them.
+
+The flags are the same as those of sync. Use --out to save the plan so it can be
+reviewed and applied later with sync apply.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if flags.resume || flags.revert {
+				return errors.New(`"resume" and "revert" cannot be planned`)
+			}
+
+			flags.plan = true
+			return syncCommand.RunE(cmd, args)
+		},
+	}
+
+	// the sync flags are shared so the plan is made exactly as a sync would make it
+	planCommand.Flags().AddFlagSet(syncCommand.Flags())
+	planCommand.Flags().StringVar(&flags.out, "out", flags.out, "path to save the plan to")
+
+	applyFlags := syncFlags{strategy: string(dictionary.Mirror)}
+
+	applyCommand := &cobra.Command{
+		Use:   "apply <plan.json>",
+		Short: "Apply the changes saved by sync plan.",
+		Long: `Apply the changes saved by sync plan.
+
+Exactly the saved changes are made. Nothing is changed if the remote dictionary
+has changed since the plan was made.
+
+A plan made with --scope only checks the keys inside its scope. Changes made
+since the plan to keys outside the scope are not detected and do not stop it
+being applied.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			p, err := dictionary.LoadPlan(args[0])
+
+			if err != nil {
+				return err
+			}
+
+			client, err := fastly.NewClient(globalConfig.FastlyAPIKey)
+
+			if err != nil {
+				return errors.Wrap(err, "cannot create fastly client")
 			}
 
-			dictInstance, err := client.GetDictionary(&fastly.GetDictionaryInput{
-				Service: serviceID,
-				Version: version,
-				Name:    dict,
-			})
+			syncer, err := applyFlags.newSyncer(client, nil, p.ServiceID, p.DictionaryID)
 
 			if err != nil {
-				return errors.Wrap(err, "error getting dictionary ID")
+				return err
 			}
 
-			syncer := dictionary.Manager(client, dictionary.WithLocalReader(reader), dictionary.WithRemoteDictionary(serviceID, dictInstance.ID))
+			result, err := syncer.ApplyPlan(p)
 
-			return syncer.Sync()
+			if err != nil {
+				return err
+			}
+
+			return printResult(os.Stdout, result, applyFlags.output)
 		},
 	}
 
-	syncCommand.Flags().StringVar(&localFile, "path", localFile, "path to file")
-	syncCommand.Flags().StringVar(&filetype, "file-type", "CSV", "type of file")
-	syncCommand.Flags().StringVar(&dict, "dict", dict, "name of dictionary to update")
-	syncCommand.Flags().StringVar(&service, "service", service, "name of service to update")
+	applyCommand.Flags().StringVar(&applyFlags.output, "output", "table", "output format (table, json)")
+	applyCommand.Flags().StringVar(&applyFlags.journal, "journal", applyFlags.journal, "path to the journal of applied batches. Defaults to a file per dictionary in the fastly-cli config directory")
+	applyCommand.Flags().BoolVar(&applyFlags.lock, "lock", false, "take a lock stored in the dictionary so concurrent syncs of it cannot interleave")
+	applyCommand.Flags().DurationVar(&applyFlags.lockTTL, "lock-ttl", 10*time.Minute, "how long the lock is held before it expires and can be stolen")
+	applyCommand.Flags().DurationVar(&applyFlags.lockWait, "lock-wait", 0, "how long to wait for a lock held by another sync. By default the apply fails immediately")
+
+	syncCommand.AddCommand(planCommand)
+	syncCommand.AddCommand(applyCommand)
+
+	root.AddCommand(syncCommand)
+	return nil
+}
+
+// syncFile syncs a single local file, or the merged layers of many files, with a dictionary
+func syncFile(client *fastly.Client, localFiles []string, filetype, service, dict string, flags syncFlags) error {
+
+	if err := flags.checkLocal(localFiles, filetype, flags.csv); err != nil {
+		return err
+	}
 
-	err := markFlagsRequired(syncCommand, "path", "dict", "service")
+	reader, err := openLocalFiles(localFiles, filetype, flags.csv)
 
 	if err != nil {
 		return err
 	}
 
-	root.AddCommand(syncCommand)
+	var remoteClient dictionaryClient = client
+	remote, err := getRemoteDictionary(client, service, dict)
+
+	if err != nil {
+
+		if !flags.createMissing || errors.Cause(err) != errDictionaryNotFound { // nolint: errorlint
+			return err
+		}
+
+		if flags.plan {
+			fmt.Fprintf(os.Stderr, "dictionary %s does not exist and would be created\n", dict)
+			remoteClient = missingDictionary{client}
+		} else {
+			ids, err := createDictionaries(client, remote.ServiceID, remote.Version, dict)
+
+			if err != nil {
+				return err
+			}
+
+			remote.DictionaryID = ids[dict]
+		}
+	}
+
+	syncer, err := flags.newSyncer(remoteClient, reader, remote.ServiceID, remote.DictionaryID)
+
+	if err != nil {
+		return err
+	}
+
+	changes, err := syncer.Plan()
+
+	if err != nil {
+		return err
+	}
+
+	if flags.plan {
+
+		if flags.out != "" {
+			if err := savePlan(syncer, changes, flags.out); err != nil {
+				return err
+			}
+		}
+		return printPlan(os.Stdout, changes, flags.output)
+	}
+
+	result, err := syncer.Apply(changes)
+
+	if err != nil {
+		return err
+	}
+
+	// when watching show what each sync changed. The JSON result already lists the changed keys.
+	if flags.watch && flags.output == "table" {
+		if err := printPlan(os.Stdout, changes, flags.output); err != nil {
+			return err
+		}
+	}
+
+	return printResult(os.Stdout, result, flags.output)
+}
+
+// savePlan saves the changes to be applied later with sync apply
+func savePlan(syncer dictionarySyncer, changes []dictionary.Change, path string) error {
+
+	p, err := syncer.SavePlan(changes)
+
+	if err != nil {
+		return err
+	}
+
+	if p.DictionaryID == "" {
+		return errors.New("cannot save a plan for a dictionary that does not exist")
+	}
+
+	return p.Save(path)
+}
+
+// lockOwner identifies this process as the holder of a dictionary lock
+func lockOwner() string {
+
+	name := os.Getenv("USER")
+
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s:%d", name, host, os.Getpid())
+}
+
+// resumeOrRevert continues or undoes the last journaled sync of a dictionary
+func resumeOrRevert(client *fastly.Client, service, dict string, flags syncFlags) error {
+
+	remote, err := getRemoteDictionary(client, service, dict)
+
+	if err != nil {
+		return err
+	}
+
+	syncer, err := flags.newSyncer(client, nil, remote.ServiceID, remote.DictionaryID)
+
+	if err != nil {
+		return err
+	}
+
+	if flags.revert {
+		return syncer.Revert()
+	}
+	return syncer.Resume()
+}
+
+type localDictionaryReader interface {
+	ReadAll() (records [][]string, err error)
+}
+
+// openLocalFiles returns a reader merging each local dictionary file as a layer in order.
+// A single file is read as a layer too so an item with the value <delete> is removed
+// rather than synced as a value.
+func openLocalFiles(paths []string, fileType string, dialect dictionary.CSVDialect) (localDictionaryReader, error) {
+
+	layers := make([]dictionary.Layer, 0, len(paths))
+
+	for _, path := range paths {
+
+		reader, err := openLocalFile(path, fileType, dialect)
+
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, dictionary.Layer{Name: path, Reader: reader})
+	}
+
+	return dictionary.NewLayeredReader(layers...), nil
+}
+
+// openLocalFile returns a reader for a local dictionary file.
+// If fileType is empty the type is detected from the file extension.
+// The dialect is only used for CSV files.
+func openLocalFile(path, fileType string, dialect dictionary.CSVDialect) (localDictionaryReader, error) {
+
+	var ft dictionary.FileType
+	var err error
+
+	if fileType == "" {
+		ft, err = dictionary.FileTypeFromPath(path)
+	} else {
+		ft, err = dictionary.ParseFileType(fileType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// read the file up front so we don't leak the handle
+	b, err := os.ReadFile(path) // nolint : gosec 'path' is passed in via the user
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening local file")
+	}
+
+	if ft == dictionary.CSV {
+		return dictionary.NewCSVReader(bytes.NewReader(b), dialect)
+	}
+
+	return dictionary.NewReader(ft, bytes.NewReader(b))
+}
+
+// redactChanges returns a copy of the changes with any sensitive values redacted
+func redactChanges(changes []dictionary.Change) []dictionary.Change {
+
+	redacted := make([]dictionary.Change, 0, len(changes))
+
+	for _, c := range changes {
+		redacted = append(redacted, c.Redact())
+	}
+	return redacted
+}
+
+// printPlan writes the changes in the requested format redacting any sensitive values
+func printPlan(w io.Writer, changes []dictionary.Change, format string) error {
+
+	changes = redactChanges(changes)
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	case "table":
+		if len(changes) == 0 {
+			fmt.Fprintln(w, "no changes")
+			return nil
+		}
+
+		layered := false
+		for _, c := range changes {
+			layered = layered || c.Origin != ""
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+		if layered {
+			fmt.Fprintln(tw, "OPERATION\tKEY\tOLD VALUE\tNEW VALUE\tLAYER")
+		} else {
+			fmt.Fprintln(tw, "OPERATION\tKEY\tOLD VALUE\tNEW VALUE")
+		}
+
+		for _, c := range changes {
+			if layered {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Operation, c.Key, c.From, c.To, c.Origin)
+			} else {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Operation, c.Key, c.From, c.To)
+			}
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
+
+// syncResult is the outcome of syncing a single dictionary of a service
+type syncResult struct {
+	Service    string `json:"service"`
+	Dictionary string `json:"dictionary"`
+	Created    int    `json:"created"`
+	Updated    int    `json:"updated"`
+	Deleted    int    `json:"deleted"`
+	Error      string `json:"error,omitempty"`
+	// Changes are the planned changes, with any sensitive values redacted, when planning
+	Changes []dictionary.Change `json:"changes,omitempty"`
+}
+
+// syncManifest syncs every dictionary in the manifest, printing a combined summary.
+// An error is returned if any dictionary fails to sync.
+func syncManifest(client *fastly.Client, path string, flags syncFlags) error {
+
+	m, err := dictionary.LoadManifest(path)
+
+	if err != nil {
+		return err
+	}
+
+	return syncServices(client, m.Services, flags)
+}
+
+// syncServices syncs the dictionaries of each service, at most flags.parallelism services
+// at a time, printing a combined summary. A failure does not stop the other services
+// but an error is returned if any dictionary fails to sync.
+func syncServices(client *fastly.Client, manifestServices []dictionary.ManifestService, flags syncFlags) error {
+
+	services, err := client.ListServices(&fastly.ListServicesInput{})
+
+	if err != nil {
+		return errors.Wrap(err, "error searching fastly for services")
+	}
+
+	byName := map[string]*fastly.Service{}
+	for _, s := range services {
+		byName[s.Name] = s
+	}
+
+	parallelism := flags.parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	perService := make([][]syncResult, len(manifestServices))
+	limit := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+
+	for i := range manifestServices {
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			perService[i] = syncService(client, byName[manifestServices[i].Name], manifestServices[i], flags)
+		}(i)
+	}
+
+	wg.Wait()
+
+	results := []syncResult{}
+	failed := 0
+
+	for i := range perService {
+		for _, r := range perService[i] {
+			if r.Error != "" {
+				failed++
+			}
+			results = append(results, r)
+		}
+	}
+
+	// the JSON results include the planned changes of each dictionary
+	if flags.plan && flags.output == "table" {
+		if err := printSyncPlans(os.Stdout, results); err != nil {
+			return err
+		}
+	}
+
+	if err := printSyncResults(os.Stdout, results, flags.output); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d dictionaries failed to sync", failed, len(results))
+	}
+	return nil
+}
+
+// syncService syncs each dictionary of a service returning a result per dictionary
+func syncService(client *fastly.Client, service *fastly.Service, ms dictionary.ManifestService, flags syncFlags) []syncResult {
+
+	// the local items are checked before any dictionary is looked up or created
+	localErrs := make([]error, len(ms.Dictionaries))
+	valid := []dictionary.ManifestDictionary{}
+
+	for i, md := range ms.Dictionaries {
+		if localErrs[i] = flags.forDictionary(md).checkLocal(md.Paths(), md.FileType, md.CSV); localErrs[i] == nil {
+			valid = append(valid, md)
+		}
+	}
+
+	dictionaryIDs, listErr := listDictionaryIDs(client, service)
+
+	if listErr == nil && flags.createMissing && !flags.plan {
+		listErr = createMissingDictionaries(client, service, dictionaryIDs, valid)
+	}
+
+	results := []syncResult{}
+
+	for i, md := range ms.Dictionaries {
+
+		result := syncResult{Service: ms.Name, Dictionary: md.Name}
+		err := localErrs[i]
+
+		if err == nil {
+			err = listErr
+		}
+
+		if err == nil {
+			err = syncManifestDictionary(client, service.ID, dictionaryIDs, md, flags, &result)
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+// fanOutServices returns a manifest syncing the local file to the dictionary of each named
+// service or, if match is not empty, each service fuzzy matching it
+func fanOutServices(client *fastly.Client, names []string, match string, localFiles []string, filetype, dict string, flags syncFlags) ([]dictionary.ManifestService, error) {
+
+	if match != "" {
+
+		services, err := client.ListServices(&fastly.ListServicesInput{})
+
+		if err != nil {
+			return nil, errors.Wrap(err, "error searching fastly for services")
+		}
+
+		matches := fuzzyFind(services, match)
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no services match %s", match)
+		}
+
+		names = []string{}
+		for _, s := range matches {
+			names = append(names, s.Name)
+		}
+	}
+
+	manifestServices := make([]dictionary.ManifestService, 0, len(names))
+	seen := map[string]bool{}
+
+	for _, name := range names {
+
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		manifestServices = append(manifestServices, dictionary.ManifestService{
+			Name: name,
+			Dictionaries: []dictionary.ManifestDictionary{
+				{Name: dict, Path: localFiles[0], Overlays: localFiles[1:], FileType: filetype, Rules: flags.rules, Lint: flags.lint, Scope: flags.scope, CSV: flags.csv},
+			},
+		})
+	}
+	return manifestServices, nil
+}
+
+// listDictionaryIDs returns the dictionary IDs, keyed by name, for the active version of a service
+func listDictionaryIDs(client *fastly.Client, service *fastly.Service) (map[string]string, error) {
+
+	if service == nil || service.ActiveVersion == 0 {
+		return nil, errors.New("cannot find service")
+	}
+
+	dictionaries, err := client.ListDictionaries(&fastly.ListDictionariesInput{
+		Service: service.ID,
+		Version: int(service.ActiveVersion),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing dictionaries")
+	}
+
+	ids := map[string]string{}
+	for _, d := range dictionaries {
+		ids[d.Name] = d.ID
+	}
+	return ids, nil
+}
+
+// createMissingDictionaries creates any manifest dictionaries missing from the service
+// in a single new version, adding the new IDs to dictionaryIDs
+func createMissingDictionaries(client *fastly.Client, service *fastly.Service, dictionaryIDs map[string]string, dictionaries []dictionary.ManifestDictionary) error {
+
+	missing := []string{}
+
+	for _, md := range dictionaries {
+		if _, found := dictionaryIDs[md.Name]; !found {
+			missing = append(missing, md.Name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	created, err := createDictionaries(client, service.ID, int(service.ActiveVersion), missing...)
+
+	if err != nil {
+		return err
+	}
+
+	for name, id := range created {
+		dictionaryIDs[name] = id
+	}
+	return nil
+}
+
+func syncManifestDictionary(client *fastly.Client, serviceID string, dictionaryIDs map[string]string,
+	md dictionary.ManifestDictionary, flags syncFlags, result *syncResult) error {
+
+	var remoteClient dictionaryClient = client
+	dictionaryID, found := dictionaryIDs[md.Name]
+
+	if !found {
+
+		if !flags.createMissing {
+			return errDictionaryNotFound
+		}
+
+		// only reachable when planning as missing dictionaries have already been created
+		remoteClient = missingDictionary{client}
+	}
+
+	reader, err := openLocalFiles(md.Paths(), md.FileType, md.CSV)
+
+	if err != nil {
+		return err
+	}
+
+	syncer, err := flags.forDictionary(md).newSyncer(remoteClient, reader, serviceID, dictionaryID)
+
+	if err != nil {
+		return err
+	}
+
+	changes, err := syncer.Plan()
+
+	if err != nil {
+		return err
+	}
+
+	if flags.plan {
+
+		result.Changes = redactChanges(changes)
+
+		for _, c := range changes {
+			switch c.Operation {
+			case fastly.CreateBatchOperation:
+				result.Created++
+			case fastly.UpdateBatchOperation:
+				result.Updated++
+			case fastly.DeleteBatchOperation:
+				result.Deleted++
+			}
+		}
+		return nil
+	}
+
+	applied, err := syncer.Apply(changes)
+
+	// report the batches applied before any failure
+	if applied != nil {
+		result.Created = applied.Created.Count
+		result.Updated = applied.Updated.Count
+		result.Deleted = applied.Deleted.Count
+	}
+
+	return err
+}
+
+// printResult writes the outcome of a single sync in the requested format
+func printResult(w io.Writer, result *dictionary.Result, format string) error {
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "table":
+		_, err := fmt.Fprintf(w, "%d created, %d updated, %d deleted, %d unchanged in %d batches (%s)\n",
+			result.Created.Count, result.Updated.Count, result.Deleted.Count, result.Unchanged.Count,
+			result.Batches, result.Duration.Round(time.Millisecond))
+		return err
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
+
+// printSyncPlans writes the planned changes of each dictionary that did not fail
+// headed by its service and dictionary
+func printSyncPlans(w io.Writer, results []syncResult) error {
+
+	for _, r := range results {
+
+		if r.Error != "" {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s/%s\n", r.Service, r.Dictionary)
+
+		if err := printPlan(w, r.Changes, "table"); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(w)
+	}
 	return nil
 }
+
+// printSyncResults writes the combined summary in the requested format
+func printSyncResults(w io.Writer, results []syncResult, format string) error {
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SERVICE\tDICTIONARY\tCREATED\tUPDATED\tDELETED\tERROR")
+
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n", r.Service, r.Dictionary, r.Created, r.Updated, r.Deleted, r.Error)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format : %s", format)
+}
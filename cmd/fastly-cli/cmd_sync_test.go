@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/dictionary"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OpenLocalFilesRemovesTombstones(t *testing.T) {
+
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.csv")
+	require.Nil(t, os.WriteFile(base, []byte("one,1\ntwo,<delete>\n"), 0600))
+
+	prod := filepath.Join(dir, "prod.csv")
+	require.Nil(t, os.WriteFile(prod, []byte("one,<delete>\nthree,3\n"), 0600))
+
+	testCases := []struct {
+		name     string
+		paths    []string
+		expected [][]string
+	}{
+		{
+			name:     "single file",
+			paths:    []string{base},
+			expected: [][]string{{"one", "1"}},
+		},
+		{
+			name:     "layers",
+			paths:    []string{base, prod},
+			expected: [][]string{{"three", "3"}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			reader, err := openLocalFiles(tc.paths, "", dictionary.CSVDialect{})
+			require.Nil(t, err)
+
+			records, err := reader.ReadAll()
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func Test_PrintSyncPlans(t *testing.T) {
+
+	results := []syncResult{
+		{Service: "staging", Dictionary: "flags", Created: 1, Changes: redactChanges([]dictionary.Change{
+			{Operation: fastly.CreateBatchOperation, Key: "feature-a", To: "on"},
+			{Operation: fastly.UpdateBatchOperation, Key: "api-key", From: "old", To: "new", Sensitive: true},
+		})},
+		{Service: "production", Dictionary: "flags"},
+		{Service: "broken", Dictionary: "flags", Error: "cannot find service"},
+	}
+
+	w := &bytes.Buffer{}
+	require.Nil(t, printSyncPlans(w, results))
+
+	require.Equal(t, `staging/flags
+OPERATION  KEY        OLD VALUE   NEW VALUE
+create     feature-a              on
+update     api-key    <redacted>  <redacted>
+
+production/flags
+no changes
+
+`, w.String())
+}
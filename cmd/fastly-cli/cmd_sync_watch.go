@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mdevilliers/fastly-cli/pkg/dictionary"
+	"github.com/pkg/errors"
+)
+
+// manifestPaths returns the manifest and every local and rules file it references
+func manifestPaths(path string) ([]string, error) {
+
+	m, err := dictionary.LoadManifest(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{path}
+
+	for _, s := range m.Services {
+		for _, d := range s.Dictionaries {
+			paths = append(paths, d.Paths()...)
+
+			if d.Rules != "" {
+				paths = append(paths, d.Rules)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// watch runs fn once and then again after each burst of changes to the paths
+// has settled for the debounce duration. Errors from fn are reported and watching
+// continues until the process is interrupted.
+func watch(paths []string, debounce time.Duration, fn func() error) error {
+
+	watcher, err := fsnotify.NewWatcher()
+
+	if err != nil {
+		return errors.Wrap(err, "error creating file watcher")
+	}
+
+	defer watcher.Close() // nolint: errcheck
+
+	// editors often replace files rather than write to them so
+	// watch the parent directories and filter on the file names
+	watched := map[string]bool{}
+	dirs := map[string]bool{}
+
+	for _, p := range paths {
+
+		abs, err := filepath.Abs(p)
+
+		if err != nil {
+			return errors.Wrapf(err, "error resolving %s", p)
+		}
+
+		watched[abs] = true
+		dir := filepath.Dir(abs)
+
+		if dirs[dir] {
+			continue
+		}
+
+		if err := watcher.Add(dir); err != nil {
+			return errors.Wrapf(err, "error watching %s", dir)
+		}
+		dirs[dir] = true
+	}
+
+	run := func() {
+		if err := fn(); err != nil {
+			fmt.Fprintln(os.Stderr, "error syncing :", err.Error())
+		}
+		fmt.Println("watching for changes...")
+	}
+
+	run()
+
+	stop := make(chan os.Signal, 2)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+
+			if !ok {
+				return nil
+			}
+
+			if !watched[filepath.Clean(event.Name)] || event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+
+			if !ok {
+				return nil
+			}
+
+			fmt.Fprintln(os.Stderr, "error watching :", err.Error())
+
+		case <-timer.C:
+			run()
+
+		case <-stop:
+			return nil
+		}
+	}
+}
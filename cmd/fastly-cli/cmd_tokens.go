@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/mdevilliers/fastly-cli/pkg/tokens"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -53,10 +54,14 @@ func registerTokenCommands(root *cobra.Command) error {
 			tokenManager := tokens.Manager(client)
 			token, err := tokenManager.AddToken(tokenInput)
 
+			record := audit.Record{Action: audit.TokenAdd, ServiceID: service, Resource: tokenName}
+
 			if err != nil {
-				return errors.Wrap(err, "error creating token")
+				return audit.Outcome(auditLog, record, errors.Wrap(err, "error creating token"))
 			}
 
+			auditWritten(record)
+
 			fmt.Println("API :", token.Name)
 			fmt.Println("API access token", token.AccessToken)
 
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +23,9 @@ type config struct {
 
 var globalConfig config
 
+// auditLog records every write made to Fastly
+var auditLog *audit.Log
+
 func initConfig() {
 
 	err := envconfig.Process("", &globalConfig)
@@ -29,9 +35,34 @@ func initConfig() {
 	}
 }
 
+// auditWritten records a write made to Fastly. The write has already succeeded and its
+// result, e.g. a token that is only returned once, must still be shown so failing to
+// record it is a warning.
+func auditWritten(r audit.Record) {
+	if err := auditLog.Write(r); err != nil {
+		fmt.Fprintf(os.Stderr, "warning : %v\n", err)
+	}
+}
+
+// stateDir returns the directory fastly-cli keeps local state in
+func stateDir() string {
+
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "fastly-cli")
+}
+
 func main() {
 	cobra.OnInitialize(initConfig)
 
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		auditLog = audit.New(filepath.Join(stateDir(), "audit.jsonl"), audit.WithCommand(cmd.CommandPath()))
+	}
+
 	rootCmd.PersistentFlags().StringVar(&globalConfig.FastlyAPIKey, "fastly-api-key", globalConfig.FastlyAPIKey, "Fastly API Key (export FASTLY_API_KEY=xxxx)")
 	rootCmd.PersistentFlags().StringVar(&globalConfig.FastlyUserName, "fastly-user-name", globalConfig.FastlyUserName, "Fastly user name (export FASTLY_USER_NAME=xxxx)")
 	rootCmd.PersistentFlags().StringVar(&globalConfig.FastlyUserPassword, "fastly-user-password", globalConfig.FastlyUserPassword, "Fastly user password (export FASTLY_USER_PASSWORD=xxxx)")
@@ -39,8 +70,11 @@ func main() {
 	err := registerChildCommands(rootCmd,
 		registerEavesdropCommand,
 		registerSyncCommand,
+		registerDictionaryCommands,
+		registerACLCommands,
 		registerCreateCommand,
 		registerTokenCommands,
+		registerHistoryCommand,
 		registerLaunchCommand)
 
 	if err != nil {
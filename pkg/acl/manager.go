@@ -0,0 +1,295 @@
+package acl
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+)
+
+const (
+	// https://docs.fastly.com/en/guides/about-acls
+	// ACLs are limited to 1000 entries.
+	maxEntries = 1000
+)
+
+var (
+	// ErrTooManyEntries signals the Fastly maximum entries has been reached
+	ErrTooManyEntries = errors.New("too many entries")
+)
+
+type manager struct {
+	serviceID string
+	aclID     string
+	local     localReader
+	client    remoteACLMutator
+}
+
+type option func(*manager)
+
+// WithRemoteACL allows specifying the Fastly service and ACL to use
+// NOTE : this that function requires IDs and NOT the name's of the entities
+func WithRemoteACL(serviceID, aclID string) option {
+	return func(m *manager) {
+		m.serviceID = serviceID
+		m.aclID = aclID
+	}
+}
+
+type localReader interface {
+	ReadAll() ([]Entry, error)
+}
+
+// WithLocalReader allows specifying the local ACL provider
+func WithLocalReader(reader localReader) option {
+	return func(m *manager) {
+		m.local = reader
+	}
+}
+
+type remoteACLMutator interface {
+	ListACLEntries(*fastly.ListACLEntriesInput) ([]*fastly.ACLEntry, error)
+	BatchModifyACLEntries(*fastly.BatchModifyACLEntriesInput) error
+}
+
+// Manager returns a way of syncing a local ACL with a remote one
+func Manager(client remoteACLMutator, options ...option) *manager { // nolint
+	m := &manager{
+		client: client,
+	}
+
+	for _, o := range options {
+		o(m)
+	}
+
+	return m
+}
+
+// Change is a single operation required to make the remote ACL match the local one.
+// ID is the ID of the remote entry to update or delete.
+type Change struct {
+	Operation fastly.BatchOperation `json:"op"`
+	ID        string                `json:"id,omitempty"`
+	Entry
+}
+
+// Plan returns the changes required to sync a local ACL with a remote one or returns an error.
+// No changes are made to the remote ACL.
+func (m *manager) Plan() ([]Change, error) {
+
+	localEntries, err := m.local.ReadAll()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading local acl entries")
+	}
+
+	if len(localEntries) > maxEntries {
+		return nil, ErrTooManyEntries
+	}
+
+	remoteEntries, duplicates, err := m.listRemote()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return diff(remoteEntries, duplicates, localEntries), nil
+}
+
+// Sync syncs a local ACL with a remote one returning what changed or an error
+// Local entries not remotely available are added
+// Remote entries not locally available are deleted
+// Entries whose negation or comment has changed are updated
+func (m *manager) Sync() (*Result, error) {
+
+	changes, err := m.Plan()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Apply(changes)
+}
+
+// Result counts the changes in the batches applied to the remote ACL
+type Result struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+	Batches int `json:"batches"`
+}
+
+// record adds an applied batch of changes to the result
+func (r *Result) record(changes []Change) {
+
+	for _, c := range changes {
+		switch c.Operation {
+		case fastly.CreateBatchOperation:
+			r.Created++
+		case fastly.UpdateBatchOperation:
+			r.Updated++
+		case fastly.DeleteBatchOperation:
+			r.Deleted++
+		}
+	}
+	r.Batches++
+}
+
+// Apply makes the changes to the remote ACL in batches returning what changed or an error.
+// The result counts the batches applied before any failure.
+func (m *manager) Apply(changes []Change) (*Result, error) {
+
+	result := &Result{}
+
+	for i, b := range batch(changes) {
+
+		if err := m.applyBatch(b); err != nil {
+			return result, errors.Wrapf(err, "error updating batch %d", i+1)
+		}
+
+		result.record(b)
+	}
+	return result, nil
+}
+
+func (m *manager) applyBatch(changes []Change) error {
+
+	entries := make([]*fastly.BatchACLEntry, 0, len(changes))
+
+	for _, c := range changes {
+
+		entry := &fastly.BatchACLEntry{Operation: c.Operation, ID: c.ID}
+
+		if c.Operation != fastly.DeleteBatchOperation {
+			entry.IP = c.IP
+			entry.Subnet = c.Subnet
+			entry.Negated = c.Negated
+			entry.Comment = c.Comment
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return m.client.BatchModifyACLEntries(&fastly.BatchModifyACLEntriesInput{
+		Service: m.serviceID,
+		ACL:     m.aclID,
+		Entries: entries,
+	})
+}
+
+// batch splits the changes into batches of at most fastly.BatchModifyMaximumOperations
+func batch(changes []Change) [][]Change {
+
+	batches := [][]Change{}
+
+	for len(changes) > 0 {
+
+		size := fastly.BatchModifyMaximumOperations
+		if len(changes) < size {
+			size = len(changes)
+		}
+
+		batches = append(batches, changes[:size])
+		changes = changes[size:]
+	}
+
+	return batches
+}
+
+// remoteEntry is an entry in the remote ACL and its ID
+type remoteEntry struct {
+	id string
+	Entry
+}
+
+// listRemote returns the remote entries keyed by their normalised address along with any
+// other entries for the same address e.g. 192.0.2.1/32 when 192.0.2.1 is also an entry.
+// The entry with the lowest ID is kept so the same duplicates are returned every time.
+func (m *manager) listRemote() (map[string]remoteEntry, []remoteEntry, error) {
+
+	entries, err := m.client.ListACLEntries(&fastly.ListACLEntriesInput{
+		Service: m.serviceID, ACL: m.aclID,
+	})
+
+	if err != nil {
+
+		httpError, ok := err.(*fastly.HTTPError) // nolint: errorlint
+		if ok {
+			if httpError.StatusCode == http.StatusNotFound {
+				return nil, nil, errors.New("acl not found")
+			}
+		}
+
+		return nil, nil, errors.Wrap(err, "error retrieving acl entries")
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	remote := map[string]remoteEntry{}
+	duplicates := []remoteEntry{}
+
+	for _, e := range entries {
+
+		entry := Entry{IP: e.IP, Subnet: e.Subnet, Negated: e.Negated, Comment: e.Comment}
+
+		// normalise the address so it compares with the local entries
+		if ip, subnet, err := parseAddress(entry.Address()); err == nil {
+			entry.IP = ip
+			entry.Subnet = subnet
+		}
+
+		if _, found := remote[entry.Address()]; found {
+			duplicates = append(duplicates, remoteEntry{id: e.ID, Entry: entry})
+			continue
+		}
+
+		remote[entry.Address()] = remoteEntry{id: e.ID, Entry: entry}
+	}
+
+	return remote, duplicates, nil
+}
+
+// diff returns the changes required to make remote match local. Deletes come first, so an
+// entry whose negation changes is removed before it is recreated, and each operation is
+// sorted by address. Comments are only updated when the local entry has one and
+// duplicate remote entries are always deleted.
+func diff(remote map[string]remoteEntry, duplicates []remoteEntry, local []Entry) []Change {
+
+	deletes := []Change{}
+
+	for _, d := range duplicates {
+		deletes = append(deletes, Change{Operation: fastly.DeleteBatchOperation, ID: d.id, Entry: d.Entry})
+	}
+
+	upserts := []Change{}
+	seen := map[string]bool{}
+
+	for _, l := range local {
+
+		seen[l.Address()] = true
+		r, found := remote[l.Address()]
+
+		switch {
+		case !found:
+			upserts = append(upserts, Change{Operation: fastly.CreateBatchOperation, Entry: l})
+		case r.Negated != l.Negated:
+			// negation cannot be cleared by an update
+			deletes = append(deletes, Change{Operation: fastly.DeleteBatchOperation, ID: r.id, Entry: r.Entry})
+			upserts = append(upserts, Change{Operation: fastly.CreateBatchOperation, Entry: l})
+		case l.Comment != "" && r.Comment != l.Comment:
+			upserts = append(upserts, Change{Operation: fastly.UpdateBatchOperation, ID: r.id, Entry: l})
+		}
+	}
+
+	for address, r := range remote {
+		if !seen[address] {
+			deletes = append(deletes, Change{Operation: fastly.DeleteBatchOperation, ID: r.id, Entry: r.Entry})
+		}
+	}
+
+	sort.SliceStable(deletes, func(i, j int) bool { return deletes[i].Address() < deletes[j].Address() })
+	sort.Slice(upserts, func(i, j int) bool { return upserts[i].Address() < upserts[j].Address() })
+
+	return append(deletes, upserts...)
+}
@@ -0,0 +1,181 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRemoteACL holds remote entries in memory applying batches to them
+type mockRemoteACL struct {
+	entries map[string]*fastly.ACLEntry
+	batches [][]*fastly.BatchACLEntry
+	nextID  int
+	// failOn is the 1-based batch that fails
+	failOn int
+}
+
+func (m *mockRemoteACL) ListACLEntries(i *fastly.ListACLEntriesInput) ([]*fastly.ACLEntry, error) {
+
+	entries := []*fastly.ACLEntry{}
+	for _, e := range m.entries {
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (m *mockRemoteACL) BatchModifyACLEntries(i *fastly.BatchModifyACLEntriesInput) error {
+
+	if len(m.batches)+1 == m.failOn {
+		return errors.New("!booyah")
+	}
+
+	m.batches = append(m.batches, i.Entries)
+
+	for _, e := range i.Entries {
+		switch e.Operation {
+		case fastly.CreateBatchOperation:
+			m.nextID++
+			id := fmt.Sprintf("id-%d", m.nextID)
+			m.entries[id] = &fastly.ACLEntry{ID: id, IP: e.IP, Subnet: e.Subnet, Negated: e.Negated, Comment: e.Comment}
+		case fastly.UpdateBatchOperation:
+			m.entries[e.ID].Comment = e.Comment
+		case fastly.DeleteBatchOperation:
+			delete(m.entries, e.ID)
+		}
+	}
+	return nil
+}
+
+func Test_Plan(t *testing.T) {
+
+	remote := &mockRemoteACL{
+		entries: map[string]*fastly.ACLEntry{
+			"a": {ID: "a", IP: "192.0.2.0", Subnet: "24", Comment: "old"},
+			"b": {ID: "b", IP: "192.0.2.1"},
+			"c": {ID: "c", IP: "198.51.100.7", Subnet: "32"},
+			"d": {ID: "d", IP: "203.0.113.0", Subnet: "24", Comment: "kept"},
+		},
+	}
+
+	local := NewReader(strings.NewReader(`
+192.0.2.0/24 # London
+!192.0.2.1
+198.51.100.7
+203.0.113.0/24
+2001:db8::/32
+`))
+
+	m := Manager(remote, WithLocalReader(local), WithRemoteACL("service", "acl"))
+
+	changes, err := m.Plan()
+
+	require.Nil(t, err)
+	require.Equal(t, []Change{
+		{Operation: fastly.DeleteBatchOperation, ID: "b", Entry: Entry{IP: "192.0.2.1"}},
+		{Operation: fastly.UpdateBatchOperation, ID: "a", Entry: Entry{IP: "192.0.2.0", Subnet: "24", Comment: "London"}},
+		{Operation: fastly.CreateBatchOperation, Entry: Entry{IP: "192.0.2.1", Negated: true}},
+		{Operation: fastly.CreateBatchOperation, Entry: Entry{IP: "2001:db8::", Subnet: "32"}},
+	}, changes)
+
+	// nothing is changed when planning
+	require.Len(t, remote.batches, 0)
+}
+
+func Test_DuplicateRemoteEntries(t *testing.T) {
+
+	remote := &mockRemoteACL{
+		entries: map[string]*fastly.ACLEntry{
+			"a": {ID: "a", IP: "10.0.0.1"},
+			"b": {ID: "b", IP: "10.0.0.1", Subnet: "32", Comment: "same address"},
+			"c": {ID: "c", IP: "192.0.2.0", Subnet: "24"},
+		},
+	}
+
+	local := NewReader(strings.NewReader("10.0.0.1\n192.0.2.0/24\n"))
+	m := Manager(remote, WithLocalReader(local), WithRemoteACL("service", "acl"))
+
+	changes, err := m.Plan()
+	require.Nil(t, err)
+	require.Equal(t, []Change{
+		{Operation: fastly.DeleteBatchOperation, ID: "b", Entry: Entry{IP: "10.0.0.1", Comment: "same address"}},
+	}, changes)
+
+	_, err = m.Apply(changes)
+	require.Nil(t, err)
+	require.Len(t, remote.entries, 2)
+	require.Contains(t, remote.entries, "a")
+	require.Contains(t, remote.entries, "c")
+}
+
+func Test_Sync(t *testing.T) {
+
+	remote := &mockRemoteACL{entries: map[string]*fastly.ACLEntry{}}
+
+	lines := []string{}
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+	}
+
+	local := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	m := Manager(remote, WithLocalReader(local), WithRemoteACL("service", "acl"))
+
+	result, err := m.Sync()
+	require.Nil(t, err)
+	require.Equal(t, &Result{Created: 1000, Batches: 1}, result)
+	require.Len(t, remote.entries, 1000)
+	require.Len(t, remote.batches, 1)
+
+	// a second sync makes no changes
+	local = NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	changes, err := Manager(remote, WithLocalReader(local)).Plan()
+
+	require.Nil(t, err)
+	require.Len(t, changes, 0)
+
+	// the removed entries are deleted
+	local = NewReader(strings.NewReader(strings.Join(lines[:10], "\n")))
+	result, err = Manager(remote, WithLocalReader(local)).Sync()
+	require.Nil(t, err)
+	require.Equal(t, &Result{Deleted: 990, Batches: 1}, result)
+	require.Len(t, remote.entries, 10)
+}
+
+func Test_ApplyCountsAppliedBatches(t *testing.T) {
+
+	remote := &mockRemoteACL{entries: map[string]*fastly.ACLEntry{}, failOn: 2}
+
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("id-%d", i)
+		remote.entries[id] = &fastly.ACLEntry{ID: id, IP: fmt.Sprintf("10.0.%d.%d", i/256, i%256)}
+	}
+
+	lines := []string{}
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("10.1.%d.%d", i/256, i%256))
+	}
+
+	// the deletes are applied in the first batch and the creates fail in the second
+	local := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	result, err := Manager(remote, WithLocalReader(local)).Sync()
+
+	require.NotNil(t, err)
+	require.Equal(t, &Result{Deleted: 1000, Batches: 1}, result)
+}
+
+func Test_TooManyEntries(t *testing.T) {
+
+	lines := []string{}
+	for i := 0; i < maxEntries+1; i++ {
+		lines = append(lines, fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+	}
+
+	local := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	_, err := Manager(&mockRemoteACL{}, WithLocalReader(local)).Plan()
+
+	require.Equal(t, ErrTooManyEntries, err)
+}
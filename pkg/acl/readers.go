@@ -0,0 +1,184 @@
+package acl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Entry is an IP address or CIDR range in a Fastly ACL.
+// Subnet is empty for a single address.
+type Entry struct {
+	IP      string `json:"ip"`
+	Subnet  string `json:"subnet,omitempty"`
+	Negated bool   `json:"negated,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Address returns the IP address or CIDR range of the entry
+func (e Entry) Address() string {
+
+	if e.Subnet == "" {
+		return e.IP
+	}
+	return e.IP + "/" + e.Subnet
+}
+
+// String returns the entry as it is written in a local file without the comment
+func (e Entry) String() string {
+
+	if e.Negated {
+		return "!" + e.Address()
+	}
+	return e.Address()
+}
+
+type reader struct {
+	r io.Reader
+}
+
+// NewReader returns a local ACL provider for a file of one IP address or CIDR range per line.
+// A leading '!' negates the entry, text following a '#' is the entry's comment and
+// blank lines and lines starting with '#' are ignored.
+//
+//	# office
+//	192.0.2.0/24 # London
+//	!192.0.2.1
+//	2001:db8::/32
+func NewReader(r io.Reader) *reader { // nolint
+	return &reader{r: r}
+}
+
+// ReadAll returns all of the entries in file order or ErrInvalidEntries listing every invalid line
+func (r *reader) ReadAll() ([]Entry, error) {
+
+	entries := []Entry{}
+	invalid := []InvalidEntry{}
+	seen := map[string]int{}
+
+	scanner := bufio.NewScanner(r.r)
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry, err := parseEntry(text)
+
+		if err != nil {
+			invalid = append(invalid, InvalidEntry{Line: line, Text: text, Reason: err.Error()})
+			continue
+		}
+
+		if previous, found := seen[entry.Address()]; found {
+			invalid = append(invalid, InvalidEntry{Line: line, Text: text, Reason: fmt.Sprintf("duplicate of line %d", previous)})
+			continue
+		}
+
+		seen[entry.Address()] = line
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error reading acl entries")
+	}
+
+	if len(invalid) > 0 {
+		return nil, &ErrInvalidEntries{Entries: invalid}
+	}
+
+	return entries, nil
+}
+
+func parseEntry(text string) (Entry, error) {
+
+	entry := Entry{}
+
+	if i := strings.Index(text, "#"); i > -1 {
+		entry.Comment = strings.TrimSpace(text[i+1:])
+		text = strings.TrimSpace(text[:i])
+	}
+
+	if strings.HasPrefix(text, "!") {
+		entry.Negated = true
+		text = strings.TrimSpace(strings.TrimPrefix(text, "!"))
+	}
+
+	ip, subnet, err := parseAddress(text)
+
+	if err != nil {
+		return entry, err
+	}
+
+	entry.IP = ip
+	entry.Subnet = subnet
+	return entry, nil
+}
+
+// parseAddress returns the normalised IP address and subnet of an IP address or CIDR range.
+// The subnet is empty for a single address.
+func parseAddress(text string) (string, string, error) {
+
+	if !strings.Contains(text, "/") {
+
+		ip := net.ParseIP(text)
+
+		if ip == nil {
+			return "", "", fmt.Errorf("invalid IP address %s", text)
+		}
+		return ip.String(), "", nil
+	}
+
+	ip, network, err := net.ParseCIDR(text)
+
+	if err != nil {
+		return "", "", fmt.Errorf("invalid CIDR range %s", text)
+	}
+
+	if !ip.Equal(network.IP) {
+		return "", "", fmt.Errorf("CIDR range %s has host bits set, did you mean %s", text, network)
+	}
+
+	ones, bits := network.Mask.Size()
+
+	// a full mask is a single address
+	if ones == bits {
+		return ip.String(), "", nil
+	}
+
+	return ip.String(), strconv.Itoa(ones), nil
+}
+
+// InvalidEntry is a line of a local file that is not a valid entry
+type InvalidEntry struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+// ErrInvalidEntries signals lines of a local file are not valid entries
+type ErrInvalidEntries struct {
+	Entries []InvalidEntry
+}
+
+func (e *ErrInvalidEntries) Error() string {
+
+	lines := make([]string, 0, len(e.Entries)+1)
+	lines = append(lines, fmt.Sprintf("%d invalid acl entries :", len(e.Entries)))
+
+	for _, i := range e.Entries {
+		lines = append(lines, fmt.Sprintf("  line %d : %s", i.Line, i.Reason))
+	}
+
+	return strings.Join(lines, "\n")
+}
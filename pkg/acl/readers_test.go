@@ -0,0 +1,52 @@
+package acl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Reader(t *testing.T) {
+
+	content := `
+# office
+192.0.2.0/24 # London
+! 192.0.2.1
+198.51.100.7/32
+2001:DB8::/32
+`
+
+	entries, err := NewReader(strings.NewReader(content)).ReadAll()
+
+	require.Nil(t, err)
+	require.Equal(t, []Entry{
+		{IP: "192.0.2.0", Subnet: "24", Comment: "London"},
+		{IP: "192.0.2.1", Negated: true},
+		{IP: "198.51.100.7"},
+		{IP: "2001:db8::", Subnet: "32"},
+	}, entries)
+}
+
+func Test_ReaderInvalid(t *testing.T) {
+
+	content := `192.0.2.0/24
+not-an-ip
+192.0.2.1/24
+# comment
+!192.0.2.0/24
+192.0.2.0/33
+`
+
+	_, err := NewReader(strings.NewReader(content)).ReadAll()
+
+	var invalid *ErrInvalidEntries
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, []InvalidEntry{
+		{Line: 2, Text: "not-an-ip", Reason: "invalid IP address not-an-ip"},
+		{Line: 3, Text: "192.0.2.1/24", Reason: "CIDR range 192.0.2.1/24 has host bits set, did you mean 192.0.2.0/24"},
+		{Line: 5, Text: "!192.0.2.0/24", Reason: "duplicate of line 1"},
+		{Line: 6, Text: "192.0.2.0/33", Reason: "invalid CIDR range 192.0.2.0/33"},
+	}, invalid.Entries)
+}
@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Actions recorded in the audit log
+const (
+	ServiceCreate    = "service.create"
+	TokenAdd         = "token.add"
+	VersionActivate  = "version.activate"
+	EavesdropStart   = "eavesdrop.start"
+	EavesdropDispose = "eavesdrop.dispose"
+	DictionarySync   = "dictionary.sync"
+	DictionaryResume = "dictionary.resume"
+	DictionaryRevert = "dictionary.revert"
+	ACLSync          = "acl.sync"
+)
+
+// Record is a single write made to Fastly.
+// Resource identifies what was written within the service e.g. a dictionary ID or token name.
+type Record struct {
+	Time        time.Time      `json:"time"`
+	User        string         `json:"user"`
+	Command     string         `json:"command"`
+	Action      string         `json:"action"`
+	ServiceID   string         `json:"service_id,omitempty"`
+	Resource    string         `json:"resource,omitempty"`
+	FromVersion int            `json:"from_version,omitempty"`
+	ToVersion   int            `json:"to_version,omitempty"`
+	Counts      map[string]int `json:"counts,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// Auditor writes records to an audit log such as a Log
+type Auditor interface {
+	Write(Record) error
+}
+
+// Outcome writes the record of a write to Fastly, with the error of the write if any,
+// returning that error or, if the record cannot be written, the audit error.
+// A nil Auditor records nothing.
+func Outcome(a Auditor, r Record, err error) error {
+
+	if a == nil {
+		return err
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	if auditErr := a.Write(r); auditErr != nil && err == nil {
+		return auditErr
+	}
+	return err
+}
+
+// Log appends records to a JSON lines file. A nil Log records nothing.
+type Log struct {
+	path    string
+	user    string
+	command string
+	mu      sync.Mutex
+}
+
+type option func(*Log)
+
+// WithCommand allows specifying the command recorded against each record
+func WithCommand(command string) option {
+	return func(l *Log) {
+		l.command = command
+	}
+}
+
+// WithUser allows overriding the user recorded against each record.
+// The default is the current operating system user.
+func WithUser(name string) option {
+	return func(l *Log) {
+		l.user = name
+	}
+}
+
+// New returns a Log appending to the file at path
+func New(path string, options ...option) *Log {
+
+	l := &Log{
+		path: path,
+		user: currentUser(),
+	}
+
+	for _, o := range options {
+		o(l)
+	}
+
+	return l
+}
+
+func currentUser() string {
+
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// Write appends the record setting its time, user and command. It is safe for concurrent use.
+func (l *Log) Write(r Record) error {
+
+	if l == nil {
+		return nil
+	}
+
+	r.Time = time.Now().UTC()
+	r.User = l.user
+	r.Command = l.command
+
+	b, err := json.Marshal(r)
+
+	if err != nil {
+		return errors.Wrap(err, "error encoding audit record")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return errors.Wrap(err, "error creating audit log directory")
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return errors.Wrap(err, "error opening audit log")
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close() // nolint: errcheck
+		return errors.Wrap(err, "error writing audit log")
+	}
+
+	return errors.Wrap(f.Close(), "error writing audit log")
+}
+
+// Query filters the records read from a log. Empty fields match every record.
+// Limit keeps only the most recent records.
+type Query struct {
+	ServiceID string
+	Action    string
+	Since     time.Time
+	Limit     int
+}
+
+func (q Query) matches(r Record) bool {
+
+	if q.ServiceID != "" && r.ServiceID != q.ServiceID {
+		return false
+	}
+
+	if q.Action != "" && r.Action != q.Action {
+		return false
+	}
+
+	return q.Since.IsZero() || !r.Time.Before(q.Since)
+}
+
+// Read returns the records, oldest first, in the log at path matching the query.
+// A missing log contains no records.
+func Read(path string, q Query) ([]Record, error) {
+
+	records := []Record{}
+
+	f, err := os.Open(path) // nolint : gosec 'path' is passed in via the user
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, errors.Wrap(err, "error opening audit log")
+	}
+
+	defer f.Close() // nolint: errcheck
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+		r := Record{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, errors.Wrapf(err, "error decoding audit log line %d", line)
+		}
+
+		if q.matches(r) {
+			records = append(records, r)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error reading audit log")
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+
+	return records, nil
+}
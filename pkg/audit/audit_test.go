@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteAndRead(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	log := New(path, WithUser("someone"), WithCommand("fastly-cli sync"))
+
+	require.Nil(t, log.Write(Record{Action: DictionarySync, ServiceID: "service-one", Resource: "dict", Counts: map[string]int{"created": 2}}))
+	require.Nil(t, log.Write(Record{Action: VersionActivate, ServiceID: "service-two", FromVersion: 1, ToVersion: 2}))
+	require.Nil(t, log.Write(Record{Action: DictionarySync, ServiceID: "service-two", Error: "!booyah"}))
+
+	records, err := Read(path, Query{})
+	require.Nil(t, err)
+	require.Len(t, records, 3)
+
+	first := records[0]
+	require.Equal(t, "someone", first.User)
+	require.Equal(t, "fastly-cli sync", first.Command)
+	require.Equal(t, map[string]int{"created": 2}, first.Counts)
+	require.False(t, first.Time.IsZero())
+
+	testCases := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{name: "service", query: Query{ServiceID: "service-two"}, expected: []string{VersionActivate, DictionarySync}},
+		{name: "action", query: Query{Action: DictionarySync}, expected: []string{DictionarySync, DictionarySync}},
+		{name: "limit keeps the most recent", query: Query{Limit: 1}, expected: []string{DictionarySync}},
+		{name: "since", query: Query{Since: time.Now().Add(time.Hour)}, expected: []string{}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			records, err := Read(path, tc.query)
+			require.Nil(t, err)
+
+			actions := []string{}
+			for _, r := range records {
+				actions = append(actions, r.Action)
+			}
+			require.Equal(t, tc.expected, actions)
+		})
+	}
+}
+
+func Test_ConcurrentWrites(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := New(path)
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.Nil(t, log.Write(Record{Action: DictionarySync, Resource: fmt.Sprint(i)}))
+		}(i)
+	}
+
+	wg.Wait()
+
+	records, err := Read(path, Query{})
+	require.Nil(t, err)
+	require.Len(t, records, 50)
+}
+
+func Test_NilLogAndMissingFile(t *testing.T) {
+
+	var log *Log
+	require.Nil(t, log.Write(Record{Action: DictionarySync}))
+
+	records, err := Read(filepath.Join(t.TempDir(), "missing.jsonl"), Query{})
+	require.Nil(t, err)
+	require.Len(t, records, 0)
+}
+
+type mockAuditor struct {
+	records []Record
+	err     error
+}
+
+func (m *mockAuditor) Write(r Record) error {
+	m.records = append(m.records, r)
+	return m.err
+}
+
+func Test_Outcome(t *testing.T) {
+
+	failed := errors.New("!booyah")
+	auditFailed := errors.New("disk full")
+
+	testCases := []struct {
+		name     string
+		err      error
+		auditErr error
+		expected error
+		recorded string
+	}{
+		{name: "success"},
+		{name: "failure is recorded", err: failed, expected: failed, recorded: "!booyah"},
+		{name: "audit failure is returned", auditErr: auditFailed, expected: auditFailed},
+		{name: "failure is returned before the audit failure", err: failed, auditErr: auditFailed, expected: failed, recorded: "!booyah"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			auditor := &mockAuditor{err: tc.auditErr}
+
+			err := Outcome(auditor, Record{Action: ACLSync, ServiceID: "service"}, tc.err)
+			require.Equal(t, tc.expected, err)
+			require.Equal(t, []Record{{Action: ACLSync, ServiceID: "service", Error: tc.recorded}}, auditor.records)
+		})
+	}
+
+	// a nil auditor records nothing
+	require.Equal(t, failed, Outcome(nil, Record{Action: ACLSync}, failed))
+}
@@ -2,6 +2,7 @@ package builder
 
 import (
 	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/pkg/errors"
 )
 
@@ -18,6 +19,16 @@ type builder struct {
 	serviceID      string
 	serviceVersion int
 	latestVersion  int
+	audit          audit.Auditor
+}
+
+type option func(*builder)
+
+// WithAudit allows recording each new version, and whether it was activated, to an audit log
+func WithAudit(a audit.Auditor) option {
+	return func(b *builder) {
+		b.audit = a
+	}
 }
 
 type clonerActivator interface {
@@ -27,12 +38,18 @@ type clonerActivator interface {
 
 // New returns a builder instance that will `Clone`` the current version of a service,
 // apply a series of changes and then `Activate` if no errors
-func New(client clonerActivator, serviceID string, serviceVersion int) *builder {
-	return &builder{
+func New(client clonerActivator, serviceID string, serviceVersion int, options ...option) *builder {
+	b := &builder{
 		client:         client,
 		serviceID:      serviceID,
 		serviceVersion: serviceVersion,
 	}
+
+	for _, o := range options {
+		o(b)
+	}
+
+	return b
 }
 
 func (b *builder) clone() error {
@@ -59,6 +76,14 @@ func (b *builder) Apply(fn ...serviceMutator) error {
 		return err
 	}
 
+	err := b.mutate(fn...)
+
+	// the cloned version is recorded whether or not it was activated
+	return b.record(err)
+}
+
+func (b *builder) mutate(fn ...serviceMutator) error {
+
 	info := ServiceInfo{ID: b.serviceID, Version: b.latestVersion}
 
 	for i := range fn {
@@ -68,7 +93,18 @@ func (b *builder) Apply(fn ...serviceMutator) error {
 	}
 
 	return b.activate()
+}
+
+// record writes the outcome of Apply to the audit log returning the outcome
+// or, if the audit log cannot be written, that error
+func (b *builder) record(err error) error {
 
+	return audit.Outcome(b.audit, audit.Record{
+		Action:      audit.VersionActivate,
+		ServiceID:   b.serviceID,
+		FromVersion: b.serviceVersion,
+		ToVersion:   b.latestVersion,
+	}, err)
 }
 
 func (b *builder) activate() error {
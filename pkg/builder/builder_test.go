@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/stretchr/testify/require"
 )
 
@@ -128,3 +129,39 @@ func Test_ActionErrorPassedOn(t *testing.T) {
 	require.Equal(t, originalErr, err)
 
 }
+
+type mockAuditor struct {
+	records []audit.Record
+}
+
+func (m *mockAuditor) Write(r audit.Record) error {
+	m.records = append(m.records, r)
+	return nil
+}
+
+func Test_ApplyIsAudited(t *testing.T) {
+
+	client := &mockClient{
+		cloneVersioner: func(i *fastly.CloneVersionInput) (*fastly.Version, error) {
+			return &fastly.Version{Number: 2, ServiceID: "foo"}, nil
+		},
+		activateVersioner: func(i *fastly.ActivateVersionInput) (*fastly.Version, error) {
+			return &fastly.Version{Number: 2}, nil
+		},
+	}
+
+	auditor := &mockAuditor{}
+
+	require.Nil(t, New(client, "foo", 1, WithAudit(auditor)).Apply())
+
+	fn := func(current ServiceInfo) error {
+		return errors.New("booyah")
+	}
+
+	require.NotNil(t, New(client, "foo", 1, WithAudit(auditor)).Apply(fn))
+
+	require.Equal(t, []audit.Record{
+		{Action: audit.VersionActivate, ServiceID: "foo", FromVersion: 1, ToVersion: 2},
+		{Action: audit.VersionActivate, ServiceID: "foo", FromVersion: 1, ToVersion: 2, Error: "booyah"},
+	}, auditor.records)
+}
@@ -0,0 +1,34 @@
+package dictionary
+
+import (
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
+)
+
+// WithAudit allows recording each sync, resume and revert to an audit log
+func WithAudit(a audit.Auditor) option {
+	return func(m *manager) {
+		m.audit = a
+	}
+}
+
+// record writes the outcome of applying changes to the audit log returning the
+// outcome or, if the audit log cannot be written, that error. A sync that applied
+// nothing, and did not fail, made no write so is not recorded.
+func (m *manager) record(action string, result *Result, err error) error {
+
+	if err == nil && result.Batches == 0 {
+		return nil
+	}
+
+	return audit.Outcome(m.audit, audit.Record{
+		Action:    action,
+		ServiceID: m.serviceID,
+		Resource:  m.dictionaryID,
+		Counts: map[string]int{
+			"created": result.Created.Count,
+			"updated": result.Updated.Count,
+			"deleted": result.Deleted.Count,
+			"batches": result.Batches,
+		},
+	}, err)
+}
@@ -0,0 +1,55 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditor struct {
+	records []audit.Record
+}
+
+func (m *mockAuditor) Write(r audit.Record) error {
+	m.records = append(m.records, r)
+	return nil
+}
+
+func Test_SyncIsAudited(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value"}, failOn: 2}
+
+	local := &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return [][]string{{"two-key", "two-value"}}, nil
+		},
+	}
+
+	auditor := &mockAuditor{}
+	m := Manager(remote, WithLocalReader(local), WithRemoteDictionary("service", "dictionary"), WithAudit(auditor))
+
+	_, err := m.Sync()
+	require.Nil(t, err)
+
+	// a sync with no changes is not recorded
+	_, err = m.Sync()
+	require.Nil(t, err)
+
+	// the second batch fails
+	remote.items["three-key"] = "three-value"
+	_, err = m.Sync()
+	require.NotNil(t, err)
+
+	require.Equal(t, []audit.Record{
+		{
+			Action: audit.DictionarySync, ServiceID: "service", Resource: "dictionary",
+			Counts: map[string]int{"created": 1, "updated": 0, "deleted": 1, "batches": 1},
+		},
+		{
+			Action: audit.DictionarySync, ServiceID: "service", Resource: "dictionary",
+			Counts: map[string]int{"created": 0, "updated": 0, "deleted": 0, "batches": 0},
+			Error:  "error updating batch 1 of 1: !booyah",
+		},
+	}, auditor.records)
+}
@@ -0,0 +1,40 @@
+package dictionary
+
+import (
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+)
+
+// Compare returns the changes, sorted by key, that would make the from items match the to items.
+// Keys matching any of the ignore glob patterns, in which * also matches /, are expected to differ and are skipped.
+func Compare(from, to []*fastly.DictionaryItem, ignore ...string) ([]Change, error) {
+
+	for _, pattern := range ignore {
+		if _, err := matchKey(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid ignore pattern %s", pattern)
+		}
+	}
+
+	all := diffMaps(fastlyDictionaryItemsToMap(from), fastlyDictionaryItemsToMap(to))
+
+	changes := []Change{}
+
+	for _, c := range all {
+		if !matchesAny(c.Key, ignore) {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+func matchesAny(key string, patterns []string) bool {
+
+	for _, pattern := range patterns {
+		// patterns have already been validated
+		if matched, _ := matchKey(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
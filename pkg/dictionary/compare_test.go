@@ -0,0 +1,39 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Compare(t *testing.T) {
+
+	staging := []*fastly.DictionaryItem{
+		&fastly.DictionaryItem{ItemKey: "one-key", ItemValue: "one-value"},
+		&fastly.DictionaryItem{ItemKey: "two-key", ItemValue: "staging"},
+		&fastly.DictionaryItem{ItemKey: "host", ItemValue: "staging.example.com"},
+		&fastly.DictionaryItem{ItemKey: "backend-a", ItemValue: "staging-a"},
+		&fastly.DictionaryItem{ItemKey: "/origins/eu/a", ItemValue: "staging-eu"},
+	}
+
+	production := []*fastly.DictionaryItem{
+		&fastly.DictionaryItem{ItemKey: "two-key", ItemValue: "production"},
+		&fastly.DictionaryItem{ItemKey: "three-key", ItemValue: "three-value"},
+		&fastly.DictionaryItem{ItemKey: "host", ItemValue: "www.example.com"},
+		&fastly.DictionaryItem{ItemKey: "backend-a", ItemValue: "production-a"},
+		&fastly.DictionaryItem{ItemKey: "/origins/eu/a", ItemValue: "production-eu"},
+	}
+
+	changes, err := Compare(staging, production, "host", "backend-*", "/origins/*")
+
+	require.Nil(t, err)
+	require.Equal(t, []Change{
+		{Operation: fastly.DeleteBatchOperation, Key: "one-key", From: "one-value"},
+		{Operation: fastly.CreateBatchOperation, Key: "three-key", To: "three-value"},
+		{Operation: fastly.UpdateBatchOperation, Key: "two-key", From: "staging", To: "production"},
+	}, changes)
+
+	_, err = Compare(staging, production, "[")
+	require.NotNil(t, err)
+}
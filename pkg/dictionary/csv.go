@@ -0,0 +1,198 @@
+package dictionary
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CSVDialect describes the layout of a CSV file.
+// Delimiter is a single character, or "tab", and defaults to ",".
+// Comment is an optional single character starting lines to ignore.
+// If Header is true the first row is skipped. KeyColumn and ValueColumn name the
+// header columns holding the keys and values and imply Header. Without them the
+// first two columns are used and any others ignored unless Strict is true, when rows
+// with other columns are malformed as they are likely part of a value e.g. an unquoted delimiter.
+type CSVDialect struct {
+	Delimiter   string `yaml:"delimiter"`
+	Comment     string `yaml:"comment"`
+	Header      bool   `yaml:"header"`
+	KeyColumn   string `yaml:"key-column"`
+	ValueColumn string `yaml:"value-column"`
+	Strict      bool   `yaml:"strict"`
+}
+
+type csvReader struct {
+	r       io.Reader
+	dialect CSVDialect
+	comma   rune
+	comment rune
+	lines   map[string]int
+}
+
+// NewCSVReader returns a local dictionary provider for CSV files of the dialect or an error
+// if the dialect is invalid
+func NewCSVReader(r io.Reader, dialect CSVDialect) (*csvReader, error) { // nolint
+
+	c := &csvReader{r: r, dialect: dialect, comma: ','}
+
+	var err error
+
+	if dialect.Delimiter != "" {
+		if c.comma, err = toRune(dialect.Delimiter); err != nil {
+			return nil, errors.Wrap(err, "invalid csv delimiter")
+		}
+	}
+
+	if dialect.Comment != "" {
+		if c.comment, err = toRune(dialect.Comment); err != nil {
+			return nil, errors.Wrap(err, "invalid csv comment")
+		}
+	}
+
+	if (dialect.KeyColumn == "") != (dialect.ValueColumn == "") {
+		return nil, errors.New("csv key and value columns must be supplied together")
+	}
+
+	return c, nil
+}
+
+// toRune returns the single character of str, accepting "tab" and `\t` for a tab
+func toRune(str string) (rune, error) {
+
+	if str == "tab" || str == `\t` {
+		return '\t', nil
+	}
+
+	if utf8.RuneCountInString(str) != 1 {
+		return 0, fmt.Errorf("%s is not a single character", str)
+	}
+
+	r, _ := utf8.DecodeRuneInString(str)
+	return r, nil
+}
+
+// ReadAll returns all of the key, value pairs in file order or ErrMalformedRows
+// listing every row without a key and value or, for a Strict dialect, with extra unnamed columns
+func (c *csvReader) ReadAll() ([][]string, error) {
+
+	reader := csv.NewReader(bufio.NewReader(c.r))
+	reader.Comma = c.comma
+	reader.Comment = c.comment
+	// rows are checked for a key and value below so they can be reported by line
+	reader.FieldsPerRecord = -1
+
+	keyIndex, valueIndex := 0, 1
+	header := c.dialect.Header || c.dialect.KeyColumn != ""
+
+	records := [][]string{}
+	malformed := []MalformedRow{}
+	c.lines = map[string]int{}
+
+	for {
+		row, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				malformed = append(malformed, MalformedRow{Line: parseErr.Line, Reason: parseErr.Err.Error()})
+				continue
+			}
+			return nil, errors.Wrap(err, "error reading csv")
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if header {
+
+			header = false
+
+			if c.dialect.KeyColumn != "" {
+				if keyIndex, valueIndex, err = columnIndexes(row, c.dialect.KeyColumn, c.dialect.ValueColumn); err != nil {
+					return nil, errors.Wrapf(err, "invalid csv header on line %d", line)
+				}
+			}
+			continue
+		}
+
+		if len(row) <= keyIndex || len(row) <= valueIndex {
+			malformed = append(malformed, MalformedRow{Line: line, Reason: fmt.Sprintf("missing a key or value, found %d columns", len(row))})
+			continue
+		}
+
+		if c.dialect.Strict && c.dialect.KeyColumn == "" && len(row) > 2 {
+			malformed = append(malformed, MalformedRow{Line: line, Reason: fmt.Sprintf("expected a key and value, found %d columns", len(row))})
+			continue
+		}
+
+		records = append(records, []string{row[keyIndex], row[valueIndex]})
+		c.lines[row[keyIndex]] = line
+	}
+
+	if len(malformed) > 0 {
+		return nil, &ErrMalformedRows{Rows: malformed}
+	}
+
+	return records, nil
+}
+
+// Source returns the line the key was read from
+func (c *csvReader) Source(key string) (string, int) {
+	return "", c.lines[key]
+}
+
+func columnIndexes(header []string, keyColumn, valueColumn string) (int, int, error) {
+
+	keyIndex, valueIndex := -1, -1
+
+	for i, name := range header {
+		switch strings.TrimSpace(name) {
+		case keyColumn:
+			keyIndex = i
+		case valueColumn:
+			valueIndex = i
+		}
+	}
+
+	if keyIndex == -1 {
+		return 0, 0, fmt.Errorf("key column %s not found", keyColumn)
+	}
+
+	if valueIndex == -1 {
+		return 0, 0, fmt.Errorf("value column %s not found", valueColumn)
+	}
+
+	return keyIndex, valueIndex, nil
+}
+
+// MalformedRow is a row of a local file that is not a valid item
+type MalformedRow struct {
+	Line   int
+	Reason string
+}
+
+// ErrMalformedRows signals rows of a local file are not valid items
+type ErrMalformedRows struct {
+	Rows []MalformedRow
+}
+
+func (e *ErrMalformedRows) Error() string {
+
+	lines := make([]string, 0, len(e.Rows)+1)
+	lines = append(lines, fmt.Sprintf("%d malformed rows :", len(e.Rows)))
+
+	for _, r := range e.Rows {
+		lines = append(lines, fmt.Sprintf("  line %d : %s", r.Line, r.Reason))
+	}
+
+	return strings.Join(lines, "\n")
+}
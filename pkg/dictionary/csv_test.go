@@ -0,0 +1,137 @@
+package dictionary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CSVDialects(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dialect  CSVDialect
+		content  string
+		expected [][]string
+	}{
+		{
+			name:     "default",
+			content:  "one-key,one-value\ntwo-key,two-value\n",
+			expected: [][]string{{"one-key", "one-value"}, {"two-key", "two-value"}},
+		},
+		{
+			name:     "semicolon delimiter",
+			dialect:  CSVDialect{Delimiter: ";"},
+			content:  "one-key;one,value\n",
+			expected: [][]string{{"one-key", "one,value"}},
+		},
+		{
+			name:     "tab delimiter",
+			dialect:  CSVDialect{Delimiter: "tab"},
+			content:  "one-key\tone-value\n",
+			expected: [][]string{{"one-key", "one-value"}},
+		},
+		{
+			name:     "comments",
+			dialect:  CSVDialect{Comment: "#"},
+			content:  "# redirects\none-key,one-value\n",
+			expected: [][]string{{"one-key", "one-value"}},
+		},
+		{
+			name:     "header",
+			dialect:  CSVDialect{Header: true},
+			content:  "key,value\none-key,one-value\n",
+			expected: [][]string{{"one-key", "one-value"}},
+		},
+		{
+			name:     "named columns",
+			dialect:  CSVDialect{KeyColumn: "path", ValueColumn: "target"},
+			content:  "status,target,path\n301,/new,/old\n302,/b,/a\n",
+			expected: [][]string{{"/old", "/new"}, {"/a", "/b"}},
+		},
+		{
+			name:     "extra columns",
+			content:  "one-key,one-value,note\n",
+			expected: [][]string{{"one-key", "one-value"}},
+		},
+		{
+			name:     "strict named columns",
+			dialect:  CSVDialect{KeyColumn: "path", ValueColumn: "target", Strict: true},
+			content:  "status,target,path\n301,/new,/old\n",
+			expected: [][]string{{"/old", "/new"}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			reader, err := NewCSVReader(strings.NewReader(tc.content), tc.dialect)
+			require.Nil(t, err)
+
+			records, err := reader.ReadAll()
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func Test_CSVMalformedRows(t *testing.T) {
+
+	content := "# comment\none-key,one-value\ntwo-key\nthree-key,\"three\"value\"\nfour-key,four-value\nfive-key\nsix-key,six,value\n"
+
+	testCases := []struct {
+		name    string
+		dialect CSVDialect
+		lines   []int
+	}{
+		{name: "extra columns ignored", dialect: CSVDialect{Comment: "#"}, lines: []int{3, 4, 6}},
+		{name: "strict", dialect: CSVDialect{Comment: "#", Strict: true}, lines: []int{3, 4, 6, 7}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			reader, err := NewCSVReader(strings.NewReader(content), tc.dialect)
+			require.Nil(t, err)
+
+			_, err = reader.ReadAll()
+
+			var malformed *ErrMalformedRows
+			require.True(t, errors.As(err, &malformed))
+
+			lines := []int{}
+			for _, r := range malformed.Rows {
+				lines = append(lines, r.Line)
+			}
+			require.Equal(t, tc.lines, lines)
+		})
+	}
+}
+
+func Test_CSVInvalidDialect(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dialect CSVDialect
+	}{
+		{name: "delimiter", dialect: CSVDialect{Delimiter: ";;"}},
+		{name: "comment", dialect: CSVDialect{Comment: "//"}},
+		{name: "key column without value column", dialect: CSVDialect{KeyColumn: "path"}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCSVReader(strings.NewReader(""), tc.dialect)
+			require.NotNil(t, err)
+		})
+	}
+
+	reader, err := NewCSVReader(strings.NewReader("a,b\n"), CSVDialect{KeyColumn: "path", ValueColumn: "target"})
+	require.Nil(t, err)
+
+	_, err = reader.ReadAll()
+	require.NotNil(t, err)
+}
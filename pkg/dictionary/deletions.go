@@ -0,0 +1,97 @@
+package dictionary
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// DeletionLimit is the maximum number of remote items a sync may delete.
+// If Percent is set the limit is that percentage of the remote items, otherwise Count is used.
+type DeletionLimit struct {
+	Count   int
+	Percent float64
+}
+
+// ParseDeletionLimit returns the DeletionLimit for an absolute count e.g. "10"
+// or a percentage of the remote items e.g. "5%"
+func ParseDeletionLimit(str string) (DeletionLimit, error) {
+
+	if strings.HasSuffix(str, "%") {
+
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(str, "%"), 64)
+
+		if err != nil || percent < 0 || percent > 100 {
+			return DeletionLimit{}, fmt.Errorf("invalid deletion limit : %s", str)
+		}
+		return DeletionLimit{Percent: percent}, nil
+	}
+
+	count, err := strconv.Atoi(str)
+
+	if err != nil || count < 0 {
+		return DeletionLimit{}, fmt.Errorf("invalid deletion limit : %s", str)
+	}
+	return DeletionLimit{Count: count}, nil
+}
+
+// WithMaxDeletions allows specifying the maximum number of remote items that can be deleted.
+// Plan and Sync return ErrTooManyDeletions if the limit is exceeded. A nil limit allows any number.
+func WithMaxDeletions(limit *DeletionLimit) option {
+	return func(m *manager) {
+		m.maxDeletions = limit
+	}
+}
+
+// max returns the number of deletions allowed for a dictionary with total remote items
+func (d DeletionLimit) max(total int) int {
+
+	if d.Percent > 0 {
+		return int(math.Floor(float64(total) * d.Percent / 100))
+	}
+	return d.Count
+}
+
+func (d DeletionLimit) String() string {
+
+	if d.Percent > 0 {
+		return fmt.Sprintf("%v%%", d.Percent)
+	}
+	return strconv.Itoa(d.Count)
+}
+
+// checkDeletions returns ErrTooManyDeletions if the changes delete more items than allowed
+func (m *manager) checkDeletions(changes []Change, total int) error {
+
+	if m.maxDeletions == nil {
+		return nil
+	}
+
+	keys := []string{}
+
+	for _, c := range changes {
+		if c.Operation == fastly.DeleteBatchOperation {
+			keys = append(keys, c.Key)
+		}
+	}
+
+	if len(keys) <= m.maxDeletions.max(total) {
+		return nil
+	}
+
+	return &ErrTooManyDeletions{Limit: *m.maxDeletions, Keys: keys}
+}
+
+// ErrTooManyDeletions signals a sync would delete more remote items than allowed.
+// Keys contains every item that would have been deleted.
+type ErrTooManyDeletions struct {
+	Limit DeletionLimit
+	Keys  []string
+}
+
+func (e *ErrTooManyDeletions) Error() string {
+	return fmt.Sprintf("too many deletions (%d, max : %s) : %s", len(e.Keys), e.Limit, strings.Join(e.Keys, ", "))
+}
@@ -0,0 +1,102 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseDeletionLimit(t *testing.T) {
+	testCases := []struct {
+		str      string
+		expected DeletionLimit
+		err      bool
+	}{
+		{str: "10", expected: DeletionLimit{Count: 10}},
+		{str: "0", expected: DeletionLimit{Count: 0}},
+		{str: "5%", expected: DeletionLimit{Percent: 5}},
+		{str: "2.5%", expected: DeletionLimit{Percent: 2.5}},
+		{str: "-1", err: true},
+		{str: "101%", err: true},
+		{str: "foo", err: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.str, func(t *testing.T) {
+			limit, err := ParseDeletionLimit(tc.str)
+
+			if tc.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, limit)
+		})
+	}
+}
+
+func Test_MaxDeletions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		limit *DeletionLimit
+		err   bool
+	}{
+		{name: "no limit"},
+		{name: "under count", limit: &DeletionLimit{Count: 3}},
+		{name: "over count", limit: &DeletionLimit{Count: 1}, err: true},
+		{name: "under percent", limit: &DeletionLimit{Percent: 50}},
+		{name: "over percent", limit: &DeletionLimit{Percent: 25}, err: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			batched := false
+
+			client := &mockRemoteSource{
+				itemBatcher: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+					batched = true
+					return nil
+				},
+				itemLister: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+					return []*fastly.DictionaryItem{
+						&fastly.DictionaryItem{ItemKey: "one-key", ItemValue: "one-value"},
+						&fastly.DictionaryItem{ItemKey: "two-key", ItemValue: "two-value"},
+						&fastly.DictionaryItem{ItemKey: "three-key", ItemValue: "three-value"},
+						&fastly.DictionaryItem{ItemKey: "four-key", ItemValue: "four-value"},
+					}, nil
+				},
+			}
+
+			local := &mockLocalReader{
+				reader: func() ([][]string, error) {
+					return [][]string{
+						[]string{"one-key", "one-value"},
+						[]string{"two-key", "two-value"},
+					}, nil
+				},
+			}
+
+			m := Manager(client, WithLocalReader(local), WithMaxDeletions(tc.limit))
+
+			_, err := m.Sync()
+
+			if !tc.err {
+				require.Nil(t, err)
+				require.True(t, batched)
+				return
+			}
+
+			require.NotNil(t, err)
+			require.False(t, batched)
+
+			var deletions *ErrTooManyDeletions
+			require.True(t, errors.As(err, &deletions))
+			require.Equal(t, []string{"four-key", "three-key"}, deletions.Keys)
+		})
+	}
+}
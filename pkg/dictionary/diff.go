@@ -0,0 +1,60 @@
+package dictionary
+
+import (
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// diffMaps returns the changes, sorted by key, required to make remote match local.
+// The keys of each map are sorted and merged in a single pass.
+func diffMaps(remote, local map[string]string) []Change {
+
+	remoteKeys := sortedKeys(remote)
+	localKeys := sortedKeys(local)
+
+	changes := make([]Change, 0)
+
+	r, l := 0, 0
+
+	for r < len(remoteKeys) || l < len(localKeys) {
+
+		switch {
+		// the remaining keys are only in local
+		case r == len(remoteKeys):
+			changes = append(changes, Change{Operation: fastly.CreateBatchOperation, Key: localKeys[l], To: local[localKeys[l]]})
+			l++
+		// the remaining keys are only in remote
+		case l == len(localKeys):
+			changes = append(changes, Change{Operation: fastly.DeleteBatchOperation, Key: remoteKeys[r], From: remote[remoteKeys[r]]})
+			r++
+		case remoteKeys[r] < localKeys[l]:
+			changes = append(changes, Change{Operation: fastly.DeleteBatchOperation, Key: remoteKeys[r], From: remote[remoteKeys[r]]})
+			r++
+		case remoteKeys[r] > localKeys[l]:
+			changes = append(changes, Change{Operation: fastly.CreateBatchOperation, Key: localKeys[l], To: local[localKeys[l]]})
+			l++
+		default:
+			key := remoteKeys[r]
+			if remote[key] != local[key] {
+				changes = append(changes, Change{Operation: fastly.UpdateBatchOperation, Key: key, From: remote[key], To: local[key]})
+			}
+			r++
+			l++
+		}
+	}
+
+	return changes
+}
+
+func sortedKeys(m map[string]string) []string {
+
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package dictionary
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiffMaps(t *testing.T) {
+	testCases := []struct {
+		name     string
+		remote   map[string]string
+		local    map[string]string
+		expected []Change
+	}{
+		{
+			name:     "both empty",
+			remote:   map[string]string{},
+			local:    map[string]string{},
+			expected: []Change{},
+		},
+		{
+			name:   "remote empty",
+			remote: map[string]string{},
+			local:  map[string]string{"b": "2", "a": "1"},
+			expected: []Change{
+				{Operation: fastly.CreateBatchOperation, Key: "a", To: "1"},
+				{Operation: fastly.CreateBatchOperation, Key: "b", To: "2"},
+			},
+		},
+		{
+			name:   "local empty",
+			remote: map[string]string{"b": "2", "a": "1"},
+			local:  map[string]string{},
+			expected: []Change{
+				{Operation: fastly.DeleteBatchOperation, Key: "a", From: "1"},
+				{Operation: fastly.DeleteBatchOperation, Key: "b", From: "2"},
+			},
+		},
+		{
+			name:   "interleaved",
+			remote: map[string]string{"a": "1", "c": "3", "d": "4", "f": "6"},
+			local:  map[string]string{"b": "2", "c": "3", "d": "40", "g": "7"},
+			expected: []Change{
+				{Operation: fastly.DeleteBatchOperation, Key: "a", From: "1"},
+				{Operation: fastly.CreateBatchOperation, Key: "b", To: "2"},
+				{Operation: fastly.UpdateBatchOperation, Key: "d", From: "4", To: "40"},
+				{Operation: fastly.DeleteBatchOperation, Key: "f", From: "6"},
+				{Operation: fastly.CreateBatchOperation, Key: "g", To: "7"},
+			},
+		},
+		{
+			name:   "empty values",
+			remote: map[string]string{"a": ""},
+			local:  map[string]string{"a": "1", "b": ""},
+			expected: []Change{
+				{Operation: fastly.UpdateBatchOperation, Key: "a", From: "", To: "1"},
+				{Operation: fastly.CreateBatchOperation, Key: "b", To: ""},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, diffMaps(tc.remote, tc.local))
+		})
+	}
+}
+
+// benchmarkMaps returns remote and local maps of n items where
+// a tenth of the items are created, updated and deleted
+func benchmarkMaps(n int) (map[string]string, map[string]string) {
+
+	remote := make(map[string]string, n)
+	local := make(map[string]string, n)
+
+	for i := 0; i < n; i++ {
+
+		key := fmt.Sprintf("key-%06d", i)
+		value := fmt.Sprintf("https://example.com/%06d", i)
+
+		switch i % 10 {
+		case 0:
+			local[key] = value
+		case 1:
+			remote[key] = value
+		case 2:
+			remote[key] = value
+			local[key] = value + "/updated"
+		default:
+			remote[key] = value
+			local[key] = value
+		}
+	}
+	return remote, local
+}
+
+func benchmarkDiffMaps(b *testing.B, n int) {
+
+	remote, local := benchmarkMaps(n)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		diffMaps(remote, local)
+	}
+}
+
+func Benchmark_DiffMaps1k(b *testing.B)   { benchmarkDiffMaps(b, 1000) }
+func Benchmark_DiffMaps10k(b *testing.B)  { benchmarkDiffMaps(b, 10000) }
+func Benchmark_DiffMaps100k(b *testing.B) { benchmarkDiffMaps(b, 100000) }
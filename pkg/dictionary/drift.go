@@ -0,0 +1,357 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+)
+
+// Resolution controls how conflicts between local changes and remote drift are resolved
+type Resolution string
+
+const (
+	// Abort returns ErrConflicts if there are any conflicts
+	Abort Resolution = ""
+	// PreferLocal overwrites remote drift with the conflicting local changes
+	PreferLocal Resolution = "local"
+	// PreferRemote keeps remote drift, skipping the conflicting local changes.
+	// The skipped changes remain conflicts on later syncs.
+	PreferRemote Resolution = "remote"
+)
+
+const lastSyncVersion = 2
+
+// lastSync is the state of a remote dictionary as last written by a sync.
+// Items whose values were substituted are kept as secrets rather than items.
+type lastSync struct {
+	path    string
+	items   map[string]string
+	secrets map[string]secretItem
+}
+
+// secretItem identifies a substituted value by the template it was substituted
+// from and a hash of the value, so the secret itself is never saved
+type secretItem struct {
+	Template string `json:"template"`
+	Hash     string `json:"hash"`
+}
+
+// lastSyncFile is the saved last sync. Version 1 files are a map of every item.
+type lastSyncFile struct {
+	Version int                   `json:"version"`
+	Items   map[string]string     `json:"items"`
+	Secrets map[string]secretItem `json:"secrets,omitempty"`
+}
+
+// WithLastSync allows specifying a file to record the state of the remote dictionary after each sync.
+// On the next sync remote items changed since the last sync are treated as drift and kept unless
+// the local item has also changed, which is a conflict resolved according to WithConflictResolution.
+func WithLastSync(path string) option {
+	return func(m *manager) {
+		m.lastSync = &lastSync{path: path}
+	}
+}
+
+// WithConflictResolution allows specifying how conflicts with remote drift are resolved.
+// The default is Abort.
+func WithConflictResolution(resolution Resolution) option {
+	return func(m *manager) {
+		m.resolution = resolution
+	}
+}
+
+// Conflict is an item changed both locally and remotely since the last sync.
+// An empty value signals the item did not exist.
+type Conflict struct {
+	Key    string `json:"key"`
+	Base   string `json:"base"`
+	Remote string `json:"remote"`
+	Local  string `json:"local"`
+}
+
+// ErrConflicts signals local changes would overwrite remote drift
+type ErrConflicts struct {
+	Conflicts []Conflict
+}
+
+func (e *ErrConflicts) Error() string {
+
+	keys := make([]string, 0, len(e.Conflicts))
+
+	for _, c := range e.Conflicts {
+		keys = append(keys, c.Key)
+	}
+
+	return fmt.Sprintf("remote items changed since the last sync (%d conflicts) : %s", len(keys), strings.Join(keys, ", "))
+}
+
+// resolveDrift merges the local and remote items with the last sync as their base returning
+// the changes to make once any conflicts have been resolved. Remote drift is kept where the
+// local item has not changed, and conflicts where it has.
+func (m *manager) resolveDrift(changes []Change, remote map[string]string) ([]Change, error) {
+
+	m.keptDrift = map[string]bool{}
+
+	if m.lastSync == nil {
+		return changes, nil
+	}
+
+	if err := m.lastSync.load(); err != nil {
+		return nil, err
+	}
+
+	// nothing has been synced yet so there is nothing to drift from
+	if m.lastSync.items == nil {
+		return changes, nil
+	}
+
+	resolved := make([]Change, 0, len(changes))
+	conflicts := []Conflict{}
+
+	for _, c := range changes {
+
+		current, inRemote := remote[c.Key]
+
+		if m.lastSync.unchanged(c.Key, current, inRemote) {
+			resolved = append(resolved, c)
+			continue
+		}
+
+		// only the remote item has changed so it is kept
+		if m.lastSync.unchanged(c.Key, c.To, c.Operation != fastly.DeleteBatchOperation) {
+			m.keptDrift[c.Key] = true
+			continue
+		}
+
+		base := m.lastSync.items[c.Key]
+		if _, secret := m.lastSync.secrets[c.Key]; secret {
+			base = Redacted
+		}
+
+		switch m.resolution {
+		case PreferLocal:
+			resolved = append(resolved, c)
+		case PreferRemote:
+			// leave the remote item as it is
+			m.keptDrift[c.Key] = true
+		default:
+			conflicts = append(conflicts, Conflict{Key: c.Key, Base: base, Remote: current, Local: c.To})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &ErrConflicts{Conflicts: conflicts}
+	}
+
+	return resolved, nil
+}
+
+// startRecording begins recording the state of the remote dictionary from
+// its state before any of the journal batches were applied
+func (m *manager) startRecording() error {
+
+	if m.lastSync == nil {
+		return nil
+	}
+
+	if err := m.lastSync.load(); err != nil {
+		return err
+	}
+
+	secrets := m.lastSync.secretsIn(m.before)
+	items := map[string]string{}
+
+	for k, v := range m.before {
+		if _, secret := secrets[k]; !secret {
+			items[k] = v
+		}
+	}
+
+	// the base of kept drift is not moved to the remote item as a later sync
+	// would then see only the local item as changed and overwrite the drift
+	for k := range m.keptDrift {
+
+		delete(items, k)
+		delete(secrets, k)
+
+		if v, found := m.lastSync.items[k]; found {
+			items[k] = v
+		}
+
+		if s, found := m.lastSync.secrets[k]; found {
+			secrets[k] = s
+		}
+	}
+
+	m.lastSync.items = items
+	m.lastSync.secrets = secrets
+
+	return m.lastSync.save()
+}
+
+// recordApplied updates the last sync with a batch of applied changes
+func (m *manager) recordApplied(changes []Change) error {
+
+	if m.lastSync == nil {
+		return nil
+	}
+
+	for _, c := range changes {
+
+		delete(m.lastSync.items, c.Key)
+		delete(m.lastSync.secrets, c.Key)
+
+		switch {
+		case c.Operation == fastly.DeleteBatchOperation:
+		case c.Sensitive:
+			template, found := m.templates[c.Key]
+			if !found {
+				template = Redacted
+			}
+			m.lastSync.secrets[c.Key] = secretItem{Template: template, Hash: secretHash(c.To)}
+		default:
+			m.lastSync.items[c.Key] = c.To
+		}
+	}
+
+	return m.lastSync.save()
+}
+
+// markRemoteSensitive flags the changes to remote items whose values were substituted
+// by a previous sync. With substitution enabled and no previous sync recorded every
+// remote value is treated as sensitive.
+func (m *manager) markRemoteSensitive(changes []Change, remote map[string]string) error {
+
+	secrets, recorded, err := m.remoteSecrets(remote)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range changes {
+		if changes[i].Operation != fastly.CreateBatchOperation {
+			_, secret := secrets[changes[i].Key]
+			changes[i].RemoteSensitive = secret || (!recorded && m.lookupEnv != nil)
+		}
+	}
+	return nil
+}
+
+// remoteSecrets returns the remote items whose values were substituted by the last
+// sync and whether there has been a sync to record them
+func (m *manager) remoteSecrets(remote map[string]string) (map[string]secretItem, bool, error) {
+
+	if m.lastSync == nil {
+		return map[string]secretItem{}, false, nil
+	}
+
+	if err := m.lastSync.load(); err != nil {
+		return nil, false, err
+	}
+
+	return m.lastSync.secretsIn(remote), m.lastSync.items != nil, nil
+}
+
+// unchanged reports whether the remote value of key is the value last synced
+func (l *lastSync) unchanged(key, value string, found bool) bool {
+
+	if s, secret := l.secrets[key]; secret {
+		return found && s.Hash == secretHash(value)
+	}
+
+	base, inBase := l.items[key]
+	return found == inBase && base == value
+}
+
+// secretsIn returns the secrets last synced that the items still hold
+func (l *lastSync) secretsIn(items map[string]string) map[string]secretItem {
+
+	secrets := map[string]secretItem{}
+
+	for k, s := range l.secrets {
+		if v, found := items[k]; found && s.Hash == secretHash(v) {
+			secrets[k] = s
+		}
+	}
+	return secrets
+}
+
+// applyChanges updates the items with the changes returning the items
+func applyChanges(items map[string]string, changes []Change) map[string]string {
+
+	for _, c := range changes {
+		switch c.Operation {
+		case fastly.DeleteBatchOperation:
+			delete(items, c.Key)
+		default:
+			items[c.Key] = c.To
+		}
+	}
+	return items
+}
+
+// load reads the last sync if it has not already been read.
+// items is left nil if there has not been a sync.
+func (l *lastSync) load() error {
+
+	if l.items != nil {
+		return nil
+	}
+
+	b, err := os.ReadFile(l.path)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "error reading last sync")
+	}
+
+	f := lastSyncFile{}
+
+	// version 1 files are a map of items, which may fail to decode as a version 2 file
+	if err := json.Unmarshal(b, &f); err != nil || f.Version == 0 {
+		f = lastSyncFile{Version: lastSyncVersion}
+		if err := json.Unmarshal(b, &f.Items); err != nil {
+			return errors.Wrap(err, "error decoding last sync")
+		}
+	}
+
+	if f.Version != lastSyncVersion {
+		return fmt.Errorf("unsupported last sync version : %d", f.Version)
+	}
+
+	l.items = f.Items
+	l.secrets = f.Secrets
+
+	if l.items == nil {
+		l.items = map[string]string{}
+	}
+
+	if l.secrets == nil {
+		l.secrets = map[string]secretItem{}
+	}
+
+	return nil
+}
+
+func (l *lastSync) save() error {
+
+	b, err := json.MarshalIndent(lastSyncFile{Version: lastSyncVersion, Items: l.items, Secrets: l.secrets}, "", "  ")
+
+	if err != nil {
+		return errors.Wrap(err, "error encoding last sync")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return errors.Wrap(err, "error creating last sync directory")
+	}
+
+	// the last sync contains dictionary values so is only readable by the owner
+	return errors.Wrap(os.WriteFile(l.path, b, 0600), "error writing last sync")
+}
@@ -0,0 +1,86 @@
+package dictionary
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DriftDetection(t *testing.T) {
+	testCases := []struct {
+		name       string
+		resolution Resolution
+		expected   map[string]string
+		conflicts  []Conflict
+	}{
+		{
+			name: "abort",
+			conflicts: []Conflict{
+				{Key: "two-key", Base: "two-value", Remote: "remote-value", Local: "local-value"},
+			},
+		},
+		{
+			name:       "prefer local",
+			resolution: PreferLocal,
+			expected:   map[string]string{"one-key": "local-value", "two-key": "local-value", "three-key": "three-value", "four-key": "remote-value"},
+		},
+		{
+			name:       "prefer remote",
+			resolution: PreferRemote,
+			expected:   map[string]string{"one-key": "local-value", "two-key": "remote-value", "three-key": "three-value", "four-key": "remote-value"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			path := filepath.Join(t.TempDir(), "last-sync.json")
+			remote := &mockRemoteDictionary{items: map[string]string{}}
+
+			// the first sync records the base
+			local := localItems([]string{"one-key", "one-value"}, []string{"two-key", "two-value"}, []string{"four-key", "four-value"})
+
+			m := Manager(remote, WithLocalReader(local), WithLastSync(path))
+			_, err := m.Sync()
+			require.Nil(t, err)
+
+			// the remote drifts
+			remote.items["two-key"] = "remote-value"
+			remote.items["three-key"] = "three-value"
+			remote.items["four-key"] = "remote-value"
+
+			// the local file changes one-key and two-key but not three-key or four-key
+			local = localItems([]string{"one-key", "local-value"}, []string{"two-key", "local-value"}, []string{"four-key", "four-value"})
+
+			m = Manager(remote, WithLocalReader(local), WithLastSync(path), WithConflictResolution(tc.resolution))
+			_, err = m.Sync()
+
+			if tc.conflicts != nil {
+				var conflicts *ErrConflicts
+				require.True(t, errors.As(err, &conflicts))
+				require.Equal(t, tc.conflicts, conflicts.Conflicts)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, remote.items)
+
+			// a later sync without a resolution does not overwrite the kept drift
+			m = Manager(remote, WithLocalReader(local), WithLastSync(path))
+			changes, err := m.Plan()
+
+			if tc.resolution == PreferRemote {
+				var conflicts *ErrConflicts
+				require.True(t, errors.As(err, &conflicts))
+				require.Equal(t, []Conflict{{Key: "two-key", Base: "two-value", Remote: "remote-value", Local: "local-value"}}, conflicts.Conflicts)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Len(t, changes, 0)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package dictionary
+
+import (
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// keyFilter restricts a sync to the keys matching the prefixes
+type keyFilter struct {
+	include []string
+	exclude []string
+}
+
+// WithKeyFilter allows restricting a sync to keys starting with one of the include prefixes
+// and none of the exclude prefixes. An empty include matches every key.
+// Local and remote items outside of the filter are left untouched.
+func WithKeyFilter(include, exclude []string) option {
+	return func(m *manager) {
+		m.filter = keyFilter{include: include, exclude: exclude}
+	}
+}
+
+// allows returns true if the key is within the filter
+func (f keyFilter) allows(key string) bool {
+
+	for _, prefix := range f.exclude {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+
+	for _, prefix := range f.include {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f keyFilter) records(records [][]string) [][]string {
+
+	filtered := make([][]string, 0, len(records))
+
+	for i := range records {
+		if len(records[i]) == 0 || f.allows(records[i][0]) {
+			filtered = append(filtered, records[i])
+		}
+	}
+	return filtered
+}
+
+func (f keyFilter) items(items []*fastly.DictionaryItem) []*fastly.DictionaryItem {
+
+	filtered := make([]*fastly.DictionaryItem, 0, len(items))
+
+	for i := range items {
+		if f.allows(items[i].ItemKey) {
+			filtered = append(filtered, items[i])
+		}
+	}
+	return filtered
+}
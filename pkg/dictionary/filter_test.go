@@ -0,0 +1,68 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PromoteWithKeyFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected map[string]string
+	}{
+		{
+			name: "no filter",
+			expected: map[string]string{
+				"feature-a": "on", "feature-b": "on", "origin": "staging.example.com",
+			},
+		},
+		{
+			name:    "include",
+			include: []string{"feature-"},
+			expected: map[string]string{
+				"feature-a": "on", "feature-b": "on", "origin": "example.com", "prod-only": "true",
+			},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"feature-"},
+			exclude: []string{"feature-b"},
+			expected: map[string]string{
+				"feature-a": "on", "feature-b": "off", "origin": "example.com", "prod-only": "true",
+			},
+		},
+		{
+			name:    "exclude",
+			exclude: []string{"origin", "prod-"},
+			expected: map[string]string{
+				"feature-a": "on", "feature-b": "on", "origin": "example.com", "prod-only": "true",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			staging := &mockRemoteDictionary{
+				items: map[string]string{"feature-a": "on", "feature-b": "on", "origin": "staging.example.com"},
+			}
+
+			production := &mockRemoteDictionary{
+				items: map[string]string{"feature-b": "off", "origin": "example.com", "prod-only": "true"},
+			}
+
+			m := Manager(production,
+				WithLocalReader(NewRemoteReader(staging, "staging", "dict")),
+				WithKeyFilter(tc.include, tc.exclude),
+			)
+
+			_, err := m.Sync()
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, production.items)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package dictionary
+
+import (
+	"path"
+	"strings"
+)
+
+// globSeparator stands in for / so path.Match treats a key as a single element.
+// Dictionary keys do not contain it.
+const globSeparator = "\x00"
+
+// matchKey reports whether the key matches the glob pattern or returns an error if the
+// pattern is malformed. The syntax is that of path.Match except * and ? also match /
+// so patterns such as /redirects/* match nested keys like /redirects/en/home.
+func matchKey(pattern, key string) (bool, error) {
+	return path.Match(strings.ReplaceAll(pattern, "/", globSeparator), strings.ReplaceAll(key, "/", globSeparator))
+}
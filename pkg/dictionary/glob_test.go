@@ -0,0 +1,42 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MatchKey(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		key     string
+		matched bool
+		err     bool
+	}{
+		{pattern: "/redirects/*", key: "/redirects/home", matched: true},
+		{pattern: "/redirects/*", key: "/redirects/en/home", matched: true},
+		{pattern: "/redirects/*/home", key: "/redirects/en/gb/home", matched: true},
+		{pattern: "/redirects/*", key: "/other/home"},
+		{pattern: "team-?", key: "team-/", matched: true},
+		{pattern: "team-[/a]", key: "team-/", matched: true},
+		{pattern: `team-\*`, key: "team-a"},
+		{pattern: "origin-*", key: "origin-eu", matched: true},
+		{pattern: "[", key: "", err: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.pattern+" "+tc.key, func(t *testing.T) {
+
+			matched, err := matchKey(tc.pattern, tc.key)
+
+			if tc.err {
+				require.NotNil(t, err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.matched, matched)
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoJournal signals there is no journal to resume or revert from
+	ErrNoJournal = errors.New("no journal found")
+)
+
+// journal records the batches applied to a remote dictionary so a failed
+// sync can be resumed or reverted. It is rewritten after every batch.
+// Substituted values are saved as the templates they were substituted from.
+type journal struct {
+	path string
+
+	ServiceID    string            `json:"service_id"`
+	DictionaryID string            `json:"dictionary_id"`
+	Snapshot     map[string]string `json:"snapshot"`
+	// Sealed are the keys of the snapshot items saved as their templates
+	Sealed  []string       `json:"sealed,omitempty"`
+	Batches []journalBatch `json:"batches"`
+
+	// the templates of the substituted values in the snapshot and changes
+	snapshotTemplates map[string]string
+	changeTemplates   map[string]string
+}
+
+type journalBatch struct {
+	Changes []Change `json:"changes"`
+	Applied bool     `json:"applied"`
+}
+
+// WithJournal allows specifying a file to record each applied batch to.
+// The journal is required to Resume or Revert a sync.
+func WithJournal(path string) option {
+	return func(m *manager) {
+		m.journal = &journal{path: path}
+	}
+}
+
+// Resume applies the batches in the journal that have not been applied or returns an error
+func (m *manager) Resume() error {
+
+	if err := m.loadJournal(); err != nil {
+		return err
+	}
+
+	if err := m.lock(); err != nil {
+		return err
+	}
+
+	m.before = m.journal.state()
+
+	result := newResult()
+	err := m.applyJournal(result)
+
+	if unlockErr := m.Unlock(); err == nil {
+		err = unlockErr
+	}
+
+	return m.record(audit.DictionaryResume, result, err)
+}
+
+// Revert restores the remote dictionary to the snapshot captured in the journal
+// before the first write of the last sync or returns an error
+func (m *manager) Revert() error {
+
+	if err := m.scope.validate(); err != nil {
+		return err
+	}
+
+	if err := m.loadJournal(); err != nil {
+		return err
+	}
+
+	if err := m.lock(); err != nil {
+		return err
+	}
+
+	remoteItems, err := m.listRemote()
+
+	if err != nil {
+		m.Unlock() // nolint: errcheck
+		return err
+	}
+
+	m.before = fastlyDictionaryItemsToMap(remoteItems)
+
+	// keys outside the scope may have been written by others since the snapshot
+	changes := diffMaps(m.scope.restrict(m.before), m.scope.restrict(m.journal.Snapshot))
+
+	// secrets in the snapshot are restored, and journaled again, from their templates
+	m.templates = m.journal.snapshotTemplates
+
+	for i := range changes {
+		_, changes[i].Sensitive = m.templates[changes[i].Key]
+	}
+
+	if err := m.markRemoteSensitive(changes, m.before); err != nil {
+		m.Unlock() // nolint: errcheck
+		return err
+	}
+
+	// the revert is journaled in turn so it can itself be resumed or reverted
+	_, err = m.apply(audit.DictionaryRevert, changes)
+	return err
+}
+
+func (m *manager) loadJournal() error {
+
+	if m.journal.path == "" {
+		return ErrNoJournal
+	}
+
+	b, err := os.ReadFile(m.journal.path)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNoJournal
+		}
+		return errors.Wrap(err, "error reading journal")
+	}
+
+	if err := json.Unmarshal(b, m.journal); err != nil {
+		return errors.Wrap(err, "error decoding journal")
+	}
+
+	if m.journal.ServiceID != m.serviceID || m.journal.DictionaryID != m.dictionaryID {
+		return fmt.Errorf("journal is for a different dictionary : %s/%s", m.journal.ServiceID, m.journal.DictionaryID)
+	}
+
+	return m.unseal()
+}
+
+// unseal substitutes the values of the secret items saved as their templates
+func (m *manager) unseal() error {
+
+	j := m.journal
+	j.snapshotTemplates = map[string]string{}
+	j.changeTemplates = map[string]string{}
+
+	for _, k := range j.Sealed {
+
+		value, err := m.expandSaved(k, j.Snapshot[k])
+
+		if err != nil {
+			return err
+		}
+
+		j.snapshotTemplates[k] = j.Snapshot[k]
+		j.Snapshot[k] = value
+	}
+
+	j.Sealed = nil
+
+	for i := range j.Batches {
+		for k := range j.Batches[i].Changes {
+
+			c := &j.Batches[i].Changes[k]
+
+			if !c.Sensitive {
+				continue
+			}
+
+			value, err := m.expandSaved(c.Key, c.To)
+
+			if err != nil {
+				return err
+			}
+
+			j.changeTemplates[c.Key] = c.To
+			c.To = value
+		}
+	}
+
+	m.templates = j.changeTemplates
+	return nil
+}
+
+// expandSaved substitutes a value saved as its template
+func (m *manager) expandSaved(key, template string) (string, error) {
+
+	if template == Redacted {
+		return "", errors.Wrapf(ErrSealedSecret, "cannot restore %s", key)
+	}
+
+	value, err := m.expand(key, template)
+	return value, errors.Wrap(err, "error restoring a secret from the journal")
+}
+
+// applyJournal applies each batch not yet applied recording the progress,
+// and each applied batch in the result, as it goes
+func (m *manager) applyJournal(result *Result) error {
+
+	if err := m.startRecording(); err != nil {
+		return err
+	}
+
+	for i := range m.journal.Batches {
+
+		if m.journal.Batches[i].Applied {
+			continue
+		}
+
+		// the batches of another owner holding the lock must not interleave with ours
+		if err := m.checkLock(); err != nil {
+			return errors.Wrapf(err, "error updating batch %d of %d", i+1, len(m.journal.Batches))
+		}
+
+		if err := m.applyBatch(m.journal.Batches[i].Changes); err != nil {
+			return errors.Wrapf(err, "error updating batch %d of %d", i+1, len(m.journal.Batches))
+		}
+
+		m.journal.Batches[i].Applied = true
+		result.record(m.journal.Batches[i].Changes)
+
+		if err := m.journal.save(); err != nil {
+			return err
+		}
+
+		if err := m.recordApplied(m.journal.Batches[i].Changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// state returns the remote items from the snapshot with the applied batches
+func (j *journal) state() map[string]string {
+
+	items := map[string]string{}
+
+	for k, v := range j.Snapshot {
+		items[k] = v
+	}
+
+	for _, b := range j.Batches {
+		if b.Applied {
+			items = applyChanges(items, b.Changes)
+		}
+	}
+	return items
+}
+
+// begin starts a new journal replacing any previous one
+func (j *journal) begin(serviceID, dictionaryID string, snapshot map[string]string, batches [][]Change) error {
+
+	j.ServiceID = serviceID
+	j.DictionaryID = dictionaryID
+	j.Snapshot = snapshot
+	j.Batches = make([]journalBatch, 0, len(batches))
+
+	for i := range batches {
+		j.Batches = append(j.Batches, journalBatch{Changes: batches[i]})
+	}
+
+	return j.save()
+}
+
+// sealed returns a copy of the journal to save with substituted values replaced by
+// their templates. Remote values replaced by a change are not needed so are redacted.
+func (j *journal) sealed() *journal {
+
+	s := &journal{
+		ServiceID:    j.ServiceID,
+		DictionaryID: j.DictionaryID,
+		Snapshot:     map[string]string{},
+		Batches:      make([]journalBatch, 0, len(j.Batches)),
+	}
+
+	for k, v := range j.Snapshot {
+		if template, found := j.snapshotTemplates[k]; found {
+			v = template
+			s.Sealed = append(s.Sealed, k)
+		}
+		s.Snapshot[k] = v
+	}
+
+	sort.Strings(s.Sealed)
+
+	for _, b := range j.Batches {
+
+		changes := make([]Change, 0, len(b.Changes))
+
+		for _, c := range b.Changes {
+
+			if c.From != "" && (c.Sensitive || c.RemoteSensitive) {
+				c.From = Redacted
+			}
+
+			if c.Sensitive {
+				to, found := j.changeTemplates[c.Key]
+				if !found {
+					to = Redacted
+				}
+				c.To = to
+			}
+
+			changes = append(changes, c)
+		}
+
+		s.Batches = append(s.Batches, journalBatch{Changes: changes, Applied: b.Applied})
+	}
+
+	return s
+}
+
+// save writes the journal to a temporary file and renames it so
+// a partially written journal is never left behind. A journal without
+// a path is not saved.
+func (j *journal) save() error {
+
+	if j.path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(j.sealed(), "", "  ")
+
+	if err != nil {
+		return errors.Wrap(err, "error encoding journal")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return errors.Wrap(err, "error creating journal directory")
+	}
+
+	tmp := j.path + ".tmp"
+
+	// the journal contains dictionary values so is only readable by the owner
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return errors.Wrap(err, "error writing journal")
+	}
+
+	return errors.Wrap(os.Rename(tmp, j.path), "error writing journal")
+}
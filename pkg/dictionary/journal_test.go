@@ -0,0 +1,121 @@
+package dictionary
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRemoteDictionary holds remote items in memory applying batches to them
+type mockRemoteDictionary struct {
+	items   map[string]string
+	batches int
+	failOn  int
+}
+
+func (m *mockRemoteDictionary) ListDictionaryItems(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+
+	items := []*fastly.DictionaryItem{}
+	for k, v := range m.items {
+		items = append(items, &fastly.DictionaryItem{ItemKey: k, ItemValue: v})
+	}
+	return items, nil
+}
+
+func (m *mockRemoteDictionary) BatchModifyDictionaryItems(i *fastly.BatchModifyDictionaryItemsInput) error {
+
+	m.batches++
+
+	if m.batches == m.failOn {
+		return errors.New("!booyah")
+	}
+
+	for _, item := range i.Items {
+		switch item.Operation {
+		case fastly.CreateBatchOperation, fastly.UpdateBatchOperation, fastly.UpsertBatchOperation:
+			m.items[item.ItemKey] = item.ItemValue
+		case fastly.DeleteBatchOperation:
+			delete(m.items, item.ItemKey)
+		}
+	}
+	return nil
+}
+
+func Test_JournalResumeAndRevert(t *testing.T) {
+
+	original := map[string]string{"one-key": "one-value", "two-key": "two-value"}
+
+	remote := &mockRemoteDictionary{
+		items:  map[string]string{"one-key": "one-value", "two-key": "two-value"},
+		failOn: 2,
+	}
+
+	records := [][]string{}
+	for i := 0; i < 2500; i++ {
+		records = append(records, []string{fmt.Sprintf("key-%04d", i), "value"})
+	}
+
+	local := &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return records, nil
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+
+	m := Manager(remote, WithLocalReader(local), WithJournal(path))
+
+	// the second of three batches fails
+	_, err := m.Sync()
+	require.NotNil(t, err)
+	require.Equal(t, 1002, len(remote.items))
+
+	// resuming continues from the failed batch
+	m = Manager(remote, WithJournal(path))
+	require.Nil(t, m.Resume())
+	require.Equal(t, 2500, len(remote.items))
+	require.Equal(t, 4, remote.batches)
+
+	// nothing left to resume
+	require.Nil(t, m.Resume())
+	require.Equal(t, 4, remote.batches)
+
+	// revert restores the remote items from before the sync
+	m = Manager(remote, WithJournal(path))
+	require.Nil(t, m.Revert())
+	require.Equal(t, original, remote.items)
+}
+
+func Test_JournalMissing(t *testing.T) {
+
+	m := Manager(&mockRemoteDictionary{}, WithJournal(filepath.Join(t.TempDir(), "journal.json")))
+
+	require.Equal(t, ErrNoJournal, m.Resume())
+	require.Equal(t, ErrNoJournal, m.Revert())
+
+	m = Manager(&mockRemoteDictionary{})
+	require.Equal(t, ErrNoJournal, m.Resume())
+}
+
+func Test_JournalForDifferentDictionary(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	remote := &mockRemoteDictionary{items: map[string]string{}}
+
+	local := &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return [][]string{{"one-key", "one-value"}}, nil
+		},
+	}
+
+	m := Manager(remote, WithLocalReader(local), WithJournal(path), WithRemoteDictionary("service", "dictionary"))
+	_, err := m.Sync()
+	require.Nil(t, err)
+
+	m = Manager(remote, WithJournal(path), WithRemoteDictionary("service", "other"))
+	require.NotNil(t, m.Resume())
+}
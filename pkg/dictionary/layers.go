@@ -0,0 +1,119 @@
+package dictionary
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Tombstone is the value a layer gives a key to remove it from the layers beneath
+const Tombstone = "<delete>"
+
+// Layer is a named local source merged by a layered reader
+type Layer struct {
+	Name   string
+	Reader localReader
+}
+
+type layeredReader struct {
+	layers  []Layer
+	origins map[string]int
+}
+
+// NewLayeredReader returns a local dictionary provider merging the layers in order.
+// Items in later layers replace those of earlier layers and an item with the value Tombstone
+// removes the key. The merged items are returned in the order their keys first appear.
+func NewLayeredReader(layers ...Layer) *layeredReader { // nolint
+	return &layeredReader{layers: layers, origins: map[string]int{}}
+}
+
+// ReadAll returns the merged items or an error if any layer cannot be read
+// or contains a key more than once
+func (l *layeredReader) ReadAll() ([][]string, error) {
+
+	order := []string{}
+	values := map[string]string{}
+	origins := map[string]int{}
+
+	for index, layer := range l.layers {
+
+		records, err := layer.Reader.ReadAll()
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading layer %s", layer.Name)
+		}
+
+		seen := map[string]bool{}
+
+		for i, r := range records {
+
+			if len(r) < 2 {
+				return nil, fmt.Errorf("item %d of layer %s is missing a key or value", i+1, layer.Name)
+			}
+
+			key, value := r[0], r[1]
+
+			if seen[key] {
+				return nil, errors.Wrapf(&ErrDuplicateKey{Key: key}, "layer %s", layer.Name)
+			}
+			seen[key] = true
+
+			if _, found := origins[key]; !found {
+				order = append(order, key)
+			}
+
+			origins[key] = index
+
+			if value == Tombstone {
+				delete(values, key)
+				continue
+			}
+			values[key] = value
+		}
+	}
+
+	merged := make([][]string, 0, len(values))
+
+	for _, key := range order {
+		if value, found := values[key]; found {
+			merged = append(merged, []string{key, value})
+		}
+	}
+
+	l.origins = origins
+	return merged, nil
+}
+
+// Origin returns the name of the layer the final value of the key came from,
+// including the layer that removed it, or an empty string if no layer contains
+// the key or there is only one layer
+func (l *layeredReader) Origin(key string) string {
+
+	index, found := l.origins[key]
+
+	// a single layer is the origin of every item so is not reported
+	if !found || len(l.layers) < 2 {
+		return ""
+	}
+	return l.layers[index].Name
+}
+
+// Source returns the name of the layer the final value of the key came from
+// and, if the layer knows it, the line it was read from
+func (l *layeredReader) Source(key string) (string, int) {
+
+	index, found := l.origins[key]
+
+	if !found {
+		return "", 0
+	}
+
+	layer := l.layers[index]
+	line := 0
+
+	if sources, ok := layer.Reader.(sourceReader); ok {
+		_, line = sources.Source(key)
+	}
+
+	return layer.Name, line
+}
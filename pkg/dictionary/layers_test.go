@@ -0,0 +1,116 @@
+package dictionary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func csvLayer(t *testing.T, name, content string) Layer {
+
+	reader, err := NewCSVReader(strings.NewReader(content), CSVDialect{})
+	require.Nil(t, err)
+
+	return Layer{Name: name, Reader: reader}
+}
+
+func Test_LayeredReader(t *testing.T) {
+
+	reader := NewLayeredReader(
+		csvLayer(t, "base.csv", "origin,example.com\nfeature-a,off\nfeature-b,off\n"),
+		csvLayer(t, "prod.csv", "feature-a,on\nfeature-b,<delete>\nprod-only,true\n"),
+	)
+
+	records, err := reader.ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, [][]string{{"origin", "example.com"}, {"feature-a", "on"}, {"prod-only", "true"}}, records)
+
+	require.Equal(t, "base.csv", reader.Origin("origin"))
+	require.Equal(t, "prod.csv", reader.Origin("feature-a"))
+	require.Equal(t, "prod.csv", reader.Origin("feature-b"))
+	require.Equal(t, "", reader.Origin("missing"))
+}
+
+func Test_LayeredReaderRestoresTombstonedKey(t *testing.T) {
+
+	reader := NewLayeredReader(
+		csvLayer(t, "base.csv", "one,1\n"),
+		csvLayer(t, "staging.csv", "one,<delete>\n"),
+		csvLayer(t, "override.csv", "one,2\n"),
+	)
+
+	records, err := reader.ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, [][]string{{"one", "2"}}, records)
+	require.Equal(t, "override.csv", reader.Origin("one"))
+}
+
+func Test_LayeredReaderDuplicateKeyInLayer(t *testing.T) {
+
+	reader := NewLayeredReader(
+		csvLayer(t, "base.csv", "one,1\n"),
+		csvLayer(t, "prod.csv", "one,2\none,3\n"),
+	)
+
+	_, err := reader.ReadAll()
+
+	var duplicate *ErrDuplicateKey
+	require.True(t, errors.As(err, &duplicate))
+	require.Contains(t, err.Error(), "prod.csv")
+}
+
+func Test_PlanShowsLayerOrigins(t *testing.T) {
+
+	remote := &mockRemoteDictionary{
+		items: map[string]string{"origin": "example.com", "feature-a": "off", "feature-b": "off"},
+	}
+
+	m := Manager(remote,
+		WithLocalReader(NewLayeredReader(
+			csvLayer(t, "base.csv", "origin,example.com\nfeature-a,off\nfeature-b,off\n"),
+			csvLayer(t, "prod.csv", "feature-a,on\nfeature-b,<delete>\nprod-only,true\n"),
+		)),
+	)
+
+	changes, err := m.Plan()
+	require.Nil(t, err)
+	require.Equal(t, []Change{
+		{Operation: fastly.UpdateBatchOperation, Key: "feature-a", From: "off", To: "on", Origin: "prod.csv"},
+		{Operation: fastly.DeleteBatchOperation, Key: "feature-b", From: "off", Origin: "prod.csv"},
+		{Operation: fastly.CreateBatchOperation, Key: "prod-only", To: "true", Origin: "prod.csv"},
+	}, changes)
+}
+
+func Test_RulesCheckMergedLayers(t *testing.T) {
+
+	ten := int64(10)
+	rules := &Rules{Rules: []Rule{{Key: "limit", Integer: &IntegerRange{Max: &ten}}}}
+	require.Nil(t, rules.Rules[0].compile())
+
+	m := Manager(&mockRemoteDictionary{items: map[string]string{}},
+		WithLocalReader(NewLayeredReader(
+			// the base value is invalid but is replaced by a valid overlay
+			csvLayer(t, "base.csv", "limit,100\n"),
+			csvLayer(t, "prod.csv", "limit,5\n"),
+		)),
+		WithRules(rules),
+	)
+
+	_, err := m.Plan()
+	require.Nil(t, err)
+}
+
+func Test_LayeredReaderSingleLayer(t *testing.T) {
+
+	reader := NewLayeredReader(csvLayer(t, "base.csv", "one,1\ntwo,<delete>\n"))
+
+	records, err := reader.ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, [][]string{{"one", "1"}}, records)
+
+	// every item comes from the only layer so it is not reported
+	require.Equal(t, "", reader.Origin("one"))
+}
@@ -0,0 +1,252 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+)
+
+// LockKey is the reserved item holding the advisory lock of a dictionary.
+// It is never diffed, synced or reverted.
+const LockKey = "_fastly_cli_lock"
+
+// how often a lock held by another owner is checked while waiting
+var lockPollInterval = 2 * time.Second
+
+// LockOptions configures the advisory lock taken before a sync.
+// Owner identifies the holder of the lock and TTL is how long it is held before
+// it expires and can be stolen. Wait is how long to wait for a lock held by
+// another owner, zero failing immediately.
+type LockOptions struct {
+	Owner string
+	TTL   time.Duration
+	Wait  time.Duration
+}
+
+// WithLock allows a sync to take an advisory lock, stored as an item in the dictionary,
+// before the remote items are diffed and to release it after the last batch is applied.
+// Concurrent syncs of the same dictionary taking the lock cannot interleave their batches.
+// A nil options disables locking.
+func WithLock(options *LockOptions) option {
+	return func(m *manager) {
+		m.locking = options
+	}
+}
+
+// lockValue is the value of the lock item
+type lockValue struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// ErrLocked signals the dictionary is locked by another owner
+type ErrLocked struct {
+	Owner   string
+	Expires time.Time
+}
+
+func (e *ErrLocked) Error() string {
+	return fmt.Sprintf("dictionary is locked by %s until %s", e.Owner, e.Expires.Format(time.RFC3339))
+}
+
+// Unlock releases the lock taken by Plan if the changes will not be applied.
+// It does nothing if the lock is not held.
+func (m *manager) Unlock() error {
+
+	if !m.locked {
+		return nil
+	}
+
+	m.locked = false
+
+	held, err := m.readLock()
+
+	if err != nil {
+		return err
+	}
+
+	// the lock may have expired and been stolen
+	if held == nil || held.Owner != m.locking.Owner {
+		return nil
+	}
+
+	return errors.Wrap(m.writeLock(fastly.DeleteBatchOperation, nil), "error releasing dictionary lock")
+}
+
+// lock takes the lock, waiting for it if it is held by another owner, or returns an error
+func (m *manager) lock() error {
+
+	if m.locking == nil || m.locked {
+		return nil
+	}
+
+	deadline := time.Now().Add(m.locking.Wait)
+
+	for {
+
+		held, err := m.tryLock()
+
+		if err != nil {
+			return err
+		}
+
+		if held == nil {
+			m.locked = true
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+
+		if remaining <= 0 {
+			return &ErrLocked{Owner: held.Owner, Expires: held.Expires}
+		}
+
+		if remaining > lockPollInterval {
+			remaining = lockPollInterval
+		}
+
+		time.Sleep(remaining)
+	}
+}
+
+// tryLock takes the lock if it is free, expired or already held by the owner
+// returning nil, or returns the lock held by another owner
+func (m *manager) tryLock() (*lockValue, error) {
+
+	held, err := m.readLock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	value := &lockValue{Owner: m.locking.Owner, Expires: now.Add(m.locking.TTL)}
+
+	var writeErr error
+
+	switch {
+	case held == nil:
+		// a free lock is created so that only one owner can take it
+		writeErr = m.writeLock(fastly.CreateBatchOperation, value)
+	case held.Owner == m.locking.Owner:
+		writeErr = m.writeLock(fastly.UpdateBatchOperation, value)
+	case now.Before(held.Expires):
+		return held, nil
+	default:
+		// an expired lock is deleted and then created, as a free lock is, so that only one
+		// of the owners stealing it at the same time can take it. The delete fails if
+		// another owner deleted it first, which the create and read back settle.
+		m.writeLock(fastly.DeleteBatchOperation, nil) // nolint: errcheck
+		writeErr = m.writeLock(fastly.CreateBatchOperation, value)
+	}
+
+	// another owner may have taken the lock at the same time so it is read back
+	held, err = m.readLock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case held != nil && held.Owner != m.locking.Owner:
+		return held, nil
+	case writeErr != nil:
+		return nil, errors.Wrap(writeErr, "error taking dictionary lock")
+	case held == nil:
+		return nil, errors.New("dictionary lock was released while it was being taken")
+	}
+	return nil, nil
+}
+
+// checkLock returns an error if the lock is no longer held by the owner, as another
+// owner stole it after it expired or deleted it while stealing it at the same time
+func (m *manager) checkLock() error {
+
+	if !m.locked {
+		return nil
+	}
+
+	held, err := m.readLock()
+
+	if err != nil {
+		return err
+	}
+
+	if held != nil && held.Owner == m.locking.Owner {
+		return nil
+	}
+
+	m.locked = false
+
+	if held == nil {
+		return errors.New("dictionary lock was released by another owner")
+	}
+	return &ErrLocked{Owner: held.Owner, Expires: held.Expires}
+}
+
+// readLock returns the lock or nil if the dictionary is not locked.
+// A lock that cannot be decoded is treated as expired.
+func (m *manager) readLock() (*lockValue, error) {
+
+	items, err := m.client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+		Service: m.serviceID, Dictionary: m.dictionaryID,
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading dictionary lock")
+	}
+
+	for _, item := range items {
+
+		if item.ItemKey != LockKey {
+			continue
+		}
+
+		held := &lockValue{}
+
+		if err := json.Unmarshal([]byte(item.ItemValue), held); err != nil {
+			return &lockValue{Owner: item.ItemValue}, nil
+		}
+		return held, nil
+	}
+
+	return nil, nil
+}
+
+func (m *manager) writeLock(operation fastly.BatchOperation, value *lockValue) error {
+
+	item := &fastly.BatchDictionaryItem{Operation: operation, ItemKey: LockKey}
+
+	if value != nil {
+
+		b, err := json.Marshal(value)
+
+		if err != nil {
+			return err
+		}
+		item.ItemValue = string(b)
+	}
+
+	return m.client.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
+		Service:    m.serviceID,
+		Dictionary: m.dictionaryID,
+		Items:      []*fastly.BatchDictionaryItem{item},
+	})
+}
+
+// WithoutLock returns the items without the lock item, which is not part of the
+// dictionary when items are read by a sync, export or diff
+func WithoutLock(items []*fastly.DictionaryItem) []*fastly.DictionaryItem {
+
+	filtered := make([]*fastly.DictionaryItem, 0, len(items))
+
+	for i := range items {
+		if items[i].ItemKey != LockKey {
+			filtered = append(filtered, items[i])
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,290 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func lockItem(t *testing.T, owner string, expires time.Time) string {
+
+	b, err := json.Marshal(lockValue{Owner: owner, Expires: expires})
+	require.Nil(t, err)
+
+	return string(b)
+}
+
+func Test_SyncTakesAndReleasesLock(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value"}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"two-key", "two-value"})),
+		WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute}),
+	)
+
+	changes, err := m.Plan()
+	require.Nil(t, err)
+	require.Len(t, changes, 2)
+
+	held := lockValue{}
+	require.Nil(t, json.Unmarshal([]byte(remote.items[LockKey]), &held))
+	require.Equal(t, "job-1", held.Owner)
+
+	_, err = m.Apply(changes)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"two-key": "two-value"}, remote.items)
+}
+
+func Test_LockHeldByAnotherOwner(t *testing.T) {
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	remote := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value", LockKey: lockItem(t, "job-2", expires)}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"two-key", "two-value"})),
+		WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute}),
+	)
+
+	_, err := m.Sync()
+
+	var locked *ErrLocked
+	require.True(t, errors.As(err, &locked))
+	require.Equal(t, "job-2", locked.Owner)
+	require.True(t, expires.Equal(locked.Expires))
+	require.Equal(t, 0, remote.batches)
+}
+
+func Test_ExpiredLockIsStolen(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value", LockKey: lockItem(t, "job-2", time.Now().Add(-time.Minute))}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"one-key", "one-value"})),
+		WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute}),
+	)
+
+	// the lock item is never part of the plan
+	changes, err := m.Plan()
+	require.Nil(t, err)
+	require.Equal(t, []Change{}, changes)
+
+	_, err = m.Apply(changes)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"one-key": "one-value"}, remote.items)
+}
+
+func Test_WaitForLock(t *testing.T) {
+
+	defer func(interval time.Duration) { lockPollInterval = interval }(lockPollInterval)
+	lockPollInterval = 10 * time.Millisecond
+
+	remote := &mockRemoteDictionary{items: map[string]string{LockKey: lockItem(t, "job-2", time.Now().Add(50*time.Millisecond))}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"one-key", "one-value"})),
+		WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute, Wait: time.Second}),
+	)
+
+	_, err := m.Sync()
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"one-key": "one-value"}, remote.items)
+}
+
+func Test_UnlockAfterPlan(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"one-key", "one-value"})),
+		WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute}),
+	)
+
+	_, err := m.Plan()
+	require.Nil(t, err)
+	require.Contains(t, remote.items, LockKey)
+
+	require.Nil(t, m.Unlock())
+	require.Equal(t, map[string]string{}, remote.items)
+
+	// a lock stolen by another owner is left in place
+	remote.items[LockKey] = lockItem(t, "job-2", time.Now().Add(time.Hour))
+	require.Nil(t, m.Unlock())
+	require.Contains(t, remote.items, LockKey)
+}
+
+func Test_LockKeyIsReserved(t *testing.T) {
+
+	m := Manager(&mockRemoteDictionary{items: map[string]string{}},
+		WithLocalReader(localItems([]string{LockKey, "mine"})),
+	)
+
+	_, err := m.Plan()
+	require.NotNil(t, err)
+}
+
+func Test_LockIsNotCopied(t *testing.T) {
+
+	source := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value", LockKey: lockItem(t, "job-2", time.Now().Add(time.Hour))}}
+
+	records, err := NewRemoteReader(source, "service", "dictionary").ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, [][]string{{"one-key", "one-value"}}, records)
+}
+
+func Test_LockCreateRace(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{}}
+
+	// another owner takes the lock between it being read and created
+	racing := &mockRemoteSource{
+		itemLister: remote.ListDictionaryItems,
+		itemBatcher: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+			if i.Items[0].Operation == fastly.CreateBatchOperation && i.Items[0].ItemKey == LockKey {
+				remote.items[LockKey] = lockItem(t, "job-2", time.Now().Add(time.Hour))
+				return errors.New("duplicate key")
+			}
+			return remote.BatchModifyDictionaryItems(i)
+		},
+	}
+
+	m := Manager(racing,
+		WithLocalReader(localItems([]string{"one-key", "one-value"})),
+		WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute}),
+	)
+
+	_, err := m.Sync()
+
+	var locked *ErrLocked
+	require.True(t, errors.As(err, &locked))
+	require.Equal(t, "job-2", locked.Owner)
+	require.Equal(t, 0, remote.batches)
+}
+
+// exclusiveLock fails creating the lock item if it exists and deleting it if it does not, as the Fastly API does
+func exclusiveLock(remote *mockRemoteDictionary, before func(*fastly.BatchDictionaryItem)) *mockRemoteSource {
+	return &mockRemoteSource{
+		itemLister: remote.ListDictionaryItems,
+		itemBatcher: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+
+			item := i.Items[0]
+
+			if item.ItemKey == LockKey {
+
+				before(item)
+
+				_, exists := remote.items[LockKey]
+
+				if item.Operation == fastly.CreateBatchOperation && exists {
+					return errors.New("duplicate key")
+				}
+				if item.Operation == fastly.DeleteBatchOperation && !exists {
+					return errors.New("key not found")
+				}
+			}
+			return remote.BatchModifyDictionaryItems(i)
+		},
+	}
+}
+
+func Test_LockStealRace(t *testing.T) {
+	testCases := []struct {
+		name string
+		// the operation of the first owner on the lock before which the second owner steals it
+		interleaveAt fastly.BatchOperation
+		expected     map[string]string
+	}{
+		{
+			name:         "first deletes the lock stolen by the second",
+			interleaveAt: fastly.DeleteBatchOperation,
+			expected:     map[string]string{"one-key": "one-value"},
+		},
+		{
+			name:         "second steals after the first deletes the expired lock",
+			interleaveAt: fastly.CreateBatchOperation,
+			expected:     map[string]string{"two-key": "two-value"},
+		},
+	}
+
+	type planned struct {
+		changes []Change
+		err     error
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			remote := &mockRemoteDictionary{items: map[string]string{LockKey: lockItem(t, "job-3", time.Now().Add(-time.Minute))}}
+
+			// the second owner reads the expired lock then waits to steal it
+			stealing := make(chan struct{})
+			steal := make(chan struct{})
+			waiting := true
+
+			second := Manager(exclusiveLock(remote, func(*fastly.BatchDictionaryItem) {
+				if waiting {
+					waiting = false
+					close(stealing)
+					<-steal
+				}
+			}),
+				WithLocalReader(localItems([]string{"two-key", "two-value"})),
+				WithLock(&LockOptions{Owner: "job-2", TTL: time.Minute}),
+			)
+
+			secondPlan := make(chan planned)
+
+			go func() {
+				changes, err := second.Plan()
+				secondPlan <- planned{changes: changes, err: err}
+			}()
+
+			<-stealing
+
+			var secondPlanned planned
+			stolen := false
+
+			first := Manager(exclusiveLock(remote, func(item *fastly.BatchDictionaryItem) {
+				if item.Operation == tc.interleaveAt && !stolen {
+					stolen = true
+					close(steal)
+					secondPlanned = <-secondPlan
+				}
+			}),
+				WithLocalReader(localItems([]string{"one-key", "one-value"})),
+				WithLock(&LockOptions{Owner: "job-1", TTL: time.Minute}),
+			)
+
+			firstChanges, firstErr := first.Plan()
+			require.Nil(t, secondPlanned.err)
+
+			// both may believe they hold the lock but only one can apply its changes
+			applied := 0
+
+			if firstErr == nil {
+				if _, err := first.Apply(firstChanges); err == nil {
+					applied++
+				}
+			}
+
+			if _, err := second.Apply(secondPlanned.changes); err == nil {
+				applied++
+			}
+
+			require.Equal(t, 1, applied)
+			require.Equal(t, tc.expected, remote.items)
+		})
+	}
+}
+
+func Test_WithoutLock(t *testing.T) {
+
+	items := []*fastly.DictionaryItem{{ItemKey: "one-key"}, {ItemKey: LockKey}, {ItemKey: "two-key"}}
+
+	require.Equal(t, []*fastly.DictionaryItem{{ItemKey: "one-key"}, {ItemKey: "two-key"}}, WithoutLock(items))
+}
@@ -3,10 +3,12 @@ package dictionary
 import (
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/pkg/errors"
-	"github.com/r3labs/diff"
 )
 
 const (
@@ -26,12 +28,73 @@ var (
 type manager struct {
 	serviceID    string
 	dictionaryID string
+	strategy     Strategy
+	maxDeletions *DeletionLimit
+	journal      *journal
+	lastSync     *lastSync
+	resolution   Resolution
+	keptDrift    map[string]bool
+	before       map[string]string
+	unchanged    []string
+	lookupEnv    func(string) (string, bool)
+	templates    map[string]string
+	rules        *Rules
+	filter       keyFilter
+	scope        keyScope
+	locking      *LockOptions
+	locked       bool
+	redirects    *RedirectOptions
+	audit        audit.Auditor
 	local        localReader
 	client       remoteDictionaryMutator
 }
 
 type option func(*manager)
 
+// Strategy controls which changes are made to the remote dictionary
+type Strategy string
+
+const (
+	// Mirror creates, updates and deletes remote items so the remote matches the local dictionary
+	Mirror Strategy = "mirror"
+	// UpsertOnly creates and updates remote items but never deletes them
+	UpsertOnly Strategy = "upsert-only"
+	// CreateOnly creates remote items but never updates or deletes them
+	CreateOnly Strategy = "create-only"
+)
+
+// ParseStrategy returns the Strategy for a user supplied string or an error
+func ParseStrategy(str string) (Strategy, error) {
+
+	s := Strategy(str)
+
+	switch s {
+	case Mirror, UpsertOnly, CreateOnly:
+		return s, nil
+	}
+	return s, fmt.Errorf("unsupported strategy : %s", str)
+}
+
+// WithStrategy allows specifying which changes are made to the remote dictionary.
+// The default is Mirror.
+func WithStrategy(strategy Strategy) option {
+	return func(m *manager) {
+		m.strategy = strategy
+	}
+}
+
+// allows returns true if the strategy permits the operation
+func (s Strategy) allows(op fastly.BatchOperation) bool {
+
+	switch s {
+	case UpsertOnly:
+		return op != fastly.DeleteBatchOperation
+	case CreateOnly:
+		return op == fastly.CreateBatchOperation
+	}
+	return true
+}
+
 // WithRemoteDictionary allows specifying the Fastly service and dictionary to use
 // NOTE : this that function requires IDs and NOT the name's of the entities
 func WithRemoteDictionary(serviceID, dictionaryID string) option {
@@ -45,6 +108,17 @@ type localReader interface {
 	ReadAll() (records [][]string, err error)
 }
 
+// originReader is a local dictionary provider that knows which source each key came from
+type originReader interface {
+	Origin(key string) string
+}
+
+// sourceReader is a local dictionary provider that knows where each key was read from.
+// The file is empty if the reader does not know its name and the line is 0 if not known.
+type sourceReader interface {
+	Source(key string) (file string, line int)
+}
+
 // WithLocalReader allows specifying the local dictionary provider
 func WithLocalReader(reader localReader) option {
 	return func(m *manager) {
@@ -60,7 +134,10 @@ type remoteDictionaryMutator interface {
 // Manager returns a way of syncing a local dictionary with a remote one
 func Manager(client remoteDictionaryMutator, options ...option) *manager { // nolint
 	m := &manager{
-		client: client,
+		client:   client,
+		strategy: Mirror,
+		// changes are always applied through a journal, by default one that is not saved
+		journal: &journal{},
 	}
 
 	for _, o := range options {
@@ -70,121 +147,312 @@ func Manager(client remoteDictionaryMutator, options ...option) *manager { // no
 	return m
 }
 
-// Sync syncs a local dictionary with a remote one or returns an error
-// Local items not remotely available are added
-// Remote items not locally available are deleted
-// Changed local items are updated
-func (m *manager) Sync() error {
+// Change is a single operation required to make the remote dictionary match the local one
+type Change struct {
+	Operation fastly.BatchOperation `json:"op"`
+	Key       string                `json:"key"`
+	From      string                `json:"from,omitempty"`
+	To        string                `json:"to,omitempty"`
+	// Sensitive signals the local value contained substituted secrets
+	Sensitive bool `json:"sensitive,omitempty"`
+	// RemoteSensitive signals the remote value may have been substituted by a previous sync
+	RemoteSensitive bool `json:"remote_sensitive,omitempty"`
+	// Origin names the local layer the change came from
+	Origin string `json:"origin,omitempty"`
+}
 
-	// get all or the remote items
-	remoteItems, err := m.client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
-		Service: m.serviceID, Dictionary: m.dictionaryID,
-	})
+// Plan returns the changes required to sync a local dictionary with a remote one or returns an error.
+// No changes are made to the remote dictionary other than taking the lock, if locking is enabled,
+// which is held until the changes are applied or Unlock is called.
+func (m *manager) Plan() ([]Change, error) {
+
+	changes, err := m.plan()
 
 	if err != nil {
+		// the lock expires if it cannot be released
+		m.Unlock() // nolint: errcheck
+		return nil, err
+	}
+	return changes, nil
+}
 
-		httpError, ok := err.(*fastly.HTTPError) // nolint: errorlint
-		if ok {
-			if httpError.StatusCode == http.StatusNotFound {
-				return errors.New("dictionary not found")
-			}
-		}
+// Check validates the local items, as Plan does, without reading or changing the remote dictionary
+func (m *manager) Check() error {
+	_, err := m.readLocal()
+	return err
+}
 
-		return errors.Wrap(err, "error retrieving dictionary items")
+// readLocal reads and validates the local items returning them by key.
+// No remote calls are made.
+func (m *manager) readLocal() (map[string]string, error) {
+
+	if err := m.scope.validate(); err != nil {
+		return nil, err
 	}
 
 	localItems, err := m.local.ReadAll()
 
 	if err != nil {
-		return errors.Wrap(err, "error reading local dictionary items")
+		return nil, errors.Wrap(err, "error reading local dictionary items")
 	}
 
-	changelog, err := m.diff(remoteItems, localItems)
+	if err := m.scope.check(localItems); err != nil {
+		return nil, err
+	}
+
+	localItems = m.filter.records(localItems)
+
+	if err := m.substitute(localItems); err != nil {
+		return nil, err
+	}
+
+	if err := m.rules.check(localItems, m.source); err != nil {
+		return nil, err
+	}
+
+	localMap, err := stringSliceSliceToMap(localItems)
 
 	if err != nil {
-		return errors.Wrap(err, "error diffing remote and local dictionary items")
+		return nil, errors.Wrap(err, "error diffing remote and local dictionary items")
 	}
 
-	batchUpdates := []*fastly.BatchDictionaryItem{}
+	if _, found := localMap[LockKey]; found {
+		return nil, fmt.Errorf("%s is reserved for the dictionary lock", LockKey)
+	}
 
-	for change := range changelog {
+	if err := m.lintRedirects(localMap); err != nil {
+		return nil, err
+	}
 
-		key := changelog[change].Path[0]
+	return localMap, nil
+}
 
-		if changelog[change].Type == diff.CREATE {
+// source returns the file and line a local item was read from, if known
+func (m *manager) source(key string) (string, int) {
 
-			value := changelog[change].To.(string)
+	if sources, ok := m.local.(sourceReader); ok {
+		return sources.Source(key)
+	}
+	return "", 0
+}
 
-			batchUpdates = append(batchUpdates, &fastly.BatchDictionaryItem{
-				Operation: fastly.CreateBatchOperation,
-				ItemKey:   key,
-				ItemValue: value,
-			})
+func (m *manager) plan() ([]Change, error) {
 
-		}
+	localMap, err := m.readLocal()
 
-		if changelog[change].Type == diff.DELETE {
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(); err != nil {
+		return nil, err
+	}
+
+	remoteItems, err := m.listRemote()
+
+	if err != nil {
+		return nil, err
+	}
 
-			batchUpdates = append(batchUpdates, &fastly.BatchDictionaryItem{
-				Operation: fastly.DeleteBatchOperation,
-				ItemKey:   key,
-			})
+	// the whole remote dictionary is kept to revert to and
+	// detect drift but only the scoped and filtered items are diffed
+	remoteMap := fastlyDictionaryItemsToMap(remoteItems)
+	remoteItems = m.filter.items(m.scope.items(remoteItems))
 
+	filteredMap := fastlyDictionaryItemsToMap(remoteItems)
+	all := diffMaps(filteredMap, localMap)
+
+	origins, _ := m.local.(originReader)
+	changes := []Change{}
+
+	for _, c := range all {
+		if m.strategy.allows(c.Operation) {
+			_, c.Sensitive = m.templates[c.Key]
+			if origins != nil {
+				c.Origin = origins.Origin(c.Key)
+			}
+			changes = append(changes, c)
 		}
+	}
 
-		if changelog[change].Type == diff.UPDATE {
+	changes, err = m.resolveDrift(changes, remoteMap)
 
-			value := changelog[change].To.(string)
+	if err != nil {
+		return nil, err
+	}
 
-			batchUpdates = append(batchUpdates, &fastly.BatchDictionaryItem{
-				Operation: fastly.UpdateBatchOperation,
-				ItemKey:   key,
-				ItemValue: value,
-			})
+	if err := m.markRemoteSensitive(changes, remoteMap); err != nil {
+		return nil, err
+	}
 
+	if err := m.checkDeletions(changes, len(remoteItems)); err != nil {
+		return nil, err
+	}
+
+	m.before = remoteMap
+	m.unchanged = unchangedKeys(filteredMap, localMap, changes)
+	return changes, nil
+}
+
+// Sync syncs a local dictionary with a remote one returning what changed or an error
+// Local items not remotely available are added
+// Remote items not locally available are deleted (Mirror only)
+// Changed local items are updated (Mirror and UpsertOnly only)
+func (m *manager) Sync() (*Result, error) {
+
+	changes, err := m.Plan()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Apply(changes)
+}
+
+// Apply makes the changes to the remote dictionary in batches returning what changed or an error.
+// If a batch fails the result contains the batches applied before it.
+func (m *manager) Apply(changes []Change) (*Result, error) {
+	return m.apply(audit.DictionarySync, changes)
+}
+
+func (m *manager) apply(action string, changes []Change) (*Result, error) {
+
+	result := newResult()
+	result.Unchanged.add(m.unchanged...)
+
+	start := time.Now()
+
+	// the state of the remote dictionary before the first write is needed to
+	// revert from the journal and to record what was last synced
+	if m.before == nil && (m.journal.path != "" || m.lastSync != nil) {
+
+		remoteItems, err := m.listRemote()
+
+		if err != nil {
+			m.Unlock() // nolint: errcheck
+			return result, err
 		}
 
-		// 1000 is the maximum batch size
-		// If we have reached this amount flush the batch now
-		if len(batchUpdates) == fastly.BatchModifyMaximumOperations {
+		m.before = fastlyDictionaryItemsToMap(remoteItems)
+	}
+
+	// secrets are journaled as the templates they were substituted from
+	secrets, _, err := m.remoteSecrets(m.before)
+
+	if err != nil {
+		m.Unlock() // nolint: errcheck
+		return result, err
+	}
+
+	m.journal.snapshotTemplates = map[string]string{}
+
+	for k, s := range secrets {
+		m.journal.snapshotTemplates[k] = s.Template
+	}
+
+	m.journal.changeTemplates = m.templates
+
+	err = m.journal.begin(m.serviceID, m.dictionaryID, m.before, batch(changes))
+
+	if err != nil {
+		m.Unlock() // nolint: errcheck
+		return result, err
+	}
+
+	err = m.applyJournal(result)
+	result.Duration = time.Since(start)
 
-			err := m.client.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
-				Service:    m.serviceID,
-				Dictionary: m.dictionaryID,
-				Items:      batchUpdates,
-			})
+	if unlockErr := m.Unlock(); err == nil {
+		err = unlockErr
+	}
+
+	return result, m.record(action, result, err)
+}
+
+// unchangedKeys returns the sorted keys of the remote and local items that are not changed
+func unchangedKeys(remote, local map[string]string, changes []Change) []string {
+
+	changed := map[string]bool{}
 
-			if err != nil {
-				return errors.Wrap(err, "error updating batch")
+	for _, c := range changes {
+		changed[c.Key] = true
+	}
+
+	keys := []string{}
+
+	for _, items := range []map[string]string{remote, local} {
+		for k := range items {
+			if !changed[k] {
+				changed[k] = true
+				keys = append(keys, k)
 			}
-			batchUpdates = []*fastly.BatchDictionaryItem{}
 		}
 	}
 
-	if len(batchUpdates) == 0 {
-		return nil
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *manager) applyBatch(changes []Change) error {
+
+	batchUpdates := make([]*fastly.BatchDictionaryItem, 0, len(changes))
+
+	for i := range changes {
+
+		batchUpdates = append(batchUpdates, &fastly.BatchDictionaryItem{
+			Operation: changes[i].Operation,
+			ItemKey:   changes[i].Key,
+			ItemValue: changes[i].To,
+		})
 	}
 
-	// flush the last batch if any
 	return m.client.BatchModifyDictionaryItems(&fastly.BatchModifyDictionaryItemsInput{
 		Service:    m.serviceID,
 		Dictionary: m.dictionaryID,
 		Items:      batchUpdates,
 	})
+}
+
+// batch splits the changes into batches of at most fastly.BatchModifyMaximumOperations
+func batch(changes []Change) [][]Change {
 
+	batches := [][]Change{}
+
+	for len(changes) > 0 {
+
+		// 1000 is the maximum batch size
+		size := fastly.BatchModifyMaximumOperations
+		if len(changes) < size {
+			size = len(changes)
+		}
+
+		batches = append(batches, changes[:size])
+		changes = changes[size:]
+	}
+
+	return batches
 }
 
-func (m *manager) diff(remote []*fastly.DictionaryItem, local [][]string) (diff.Changelog, error) {
+// listRemote returns all of the remote items
+func (m *manager) listRemote() ([]*fastly.DictionaryItem, error) {
 
-	localMap, err := stringSliceSliceToMap(local)
+	remoteItems, err := m.client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+		Service: m.serviceID, Dictionary: m.dictionaryID,
+	})
 
 	if err != nil {
-		return nil, err
-	}
 
-	remoteMap := fastlyDictionaryItemsToMap(remote)
+		httpError, ok := err.(*fastly.HTTPError) // nolint: errorlint
+		if ok {
+			if httpError.StatusCode == http.StatusNotFound {
+				return nil, errors.New("dictionary not found")
+			}
+		}
+
+		return nil, errors.Wrap(err, "error retrieving dictionary items")
+	}
 
-	return diff.Diff(remoteMap, localMap)
+	return WithoutLock(remoteItems), nil
 }
 
 func fastlyDictionaryItemsToMap(a []*fastly.DictionaryItem) map[string]string {
@@ -209,6 +477,11 @@ func stringSliceSliceToMap(a [][]string) (map[string]string, error) {
 	m := map[string]string{}
 
 	for i := range a {
+
+		if len(a[i]) < 2 {
+			return nil, fmt.Errorf("item %d is missing a key or value", i+1)
+		}
+
 		k := a[i][0]
 		v := a[i][1]
 
@@ -235,7 +508,7 @@ func validateItem(key, value string) error {
 		return &ErrKeyTooLong{Key: key}
 	}
 	if len(value) > maxValueLength {
-		return &ErrValueTooLong{Key: key, Value: value}
+		return &ErrValueTooLong{Key: key, Length: len(value)}
 	}
 
 	return nil
@@ -259,12 +532,13 @@ func (k *ErrKeyTooLong) Error() string {
 	return fmt.Sprintf("key too long (max : %v) : %s", maxKeyLength, k.Key)
 }
 
-// ErrValueTooLong signals the Value is too long to be stored
+// ErrValueTooLong signals the value of Key is too long to be stored.
+// The value is not included as it may contain substituted secrets.
 type ErrValueTooLong struct {
-	Key   string
-	Value string
+	Key    string
+	Length int
 }
 
 func (v *ErrValueTooLong) Error() string {
-	return fmt.Sprintf("value too long (max : %v) : %s : %s", maxValueLength, v.Key, v.Value)
+	return fmt.Sprintf("value too long (max : %v) : %s : length %d", maxValueLength, v.Key, v.Length)
 }
@@ -162,7 +162,7 @@ func Test_DiffAndMutate(t *testing.T) {
 
 			m := Manager(client, WithLocalReader(local))
 
-			err := m.Sync()
+			_, err := m.Sync()
 
 			if tc.err == nil {
 				require.Nil(t, err)
@@ -217,8 +217,113 @@ func Test_RemoteDictionaryOption(t *testing.T) {
 
 	m := Manager(client, WithLocalReader(local), WithRemoteDictionary(service, dictionary))
 
-	err := m.Sync()
+	_, err := m.Sync()
 
 	require.Nil(t, err)
 	require.Equal(t, 4, count)
 }
+
+func Test_PlanMakesNoChanges(t *testing.T) {
+
+	client := &mockRemoteSource{
+		itemBatcher: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+			require.Fail(t, "plan should not modify the remote dictionary")
+			return nil
+		},
+		itemLister: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+			return []*fastly.DictionaryItem{
+				&fastly.DictionaryItem{ItemKey: "one-key", ItemValue: "one-value"},
+				&fastly.DictionaryItem{ItemKey: "three-key", ItemValue: "three-value"},
+			}, nil
+		},
+	}
+
+	local := &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return [][]string{
+				[]string{"two-key", "two-value"},
+				[]string{"one-key", "foo"},
+			}, nil
+		},
+	}
+
+	m := Manager(client, WithLocalReader(local))
+
+	changes, err := m.Plan()
+
+	require.Nil(t, err)
+	require.Equal(t, []Change{
+		{Operation: fastly.UpdateBatchOperation, Key: "one-key", From: "one-value", To: "foo"},
+		{Operation: fastly.DeleteBatchOperation, Key: "three-key", From: "three-value"},
+		{Operation: fastly.CreateBatchOperation, Key: "two-key", To: "two-value"},
+	}, changes)
+}
+
+func Test_Strategies(t *testing.T) {
+	testCases := []struct {
+		strategy Strategy
+		expected []fastly.BatchOperation
+	}{
+		{
+			strategy: Mirror,
+			expected: []fastly.BatchOperation{fastly.UpdateBatchOperation, fastly.DeleteBatchOperation, fastly.CreateBatchOperation},
+		},
+		{
+			strategy: UpsertOnly,
+			expected: []fastly.BatchOperation{fastly.UpdateBatchOperation, fastly.CreateBatchOperation},
+		},
+		{
+			strategy: CreateOnly,
+			expected: []fastly.BatchOperation{fastly.CreateBatchOperation},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(string(tc.strategy), func(t *testing.T) {
+
+			applied := []fastly.BatchOperation{}
+
+			client := &mockRemoteSource{
+				itemBatcher: func(i *fastly.BatchModifyDictionaryItemsInput) error {
+					for _, u := range i.Items {
+						applied = append(applied, u.Operation)
+					}
+					return nil
+				},
+				itemLister: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+					return []*fastly.DictionaryItem{
+						&fastly.DictionaryItem{ItemKey: "one-key", ItemValue: "one-value"},
+						&fastly.DictionaryItem{ItemKey: "three-key", ItemValue: "three-value"},
+					}, nil
+				},
+			}
+
+			local := &mockLocalReader{
+				reader: func() ([][]string, error) {
+					return [][]string{
+						[]string{"one-key", "foo"},
+						[]string{"two-key", "two-value"},
+					}, nil
+				},
+			}
+
+			m := Manager(client, WithLocalReader(local), WithStrategy(tc.strategy))
+
+			_, err := m.Sync()
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, applied)
+		})
+	}
+}
+
+func Test_ParseStrategy(t *testing.T) {
+
+	s, err := ParseStrategy("upsert-only")
+	require.Nil(t, err)
+	require.Equal(t, UpsertOnly, s)
+
+	_, err = ParseStrategy("foo")
+	require.NotNil(t, err)
+}
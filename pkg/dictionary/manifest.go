@@ -0,0 +1,134 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest maps Fastly services and their dictionaries to local files
+//
+//	services:
+//	  - name: my-service
+//	    dictionaries:
+//	      - name: redirects
+//	        path: ./redirects.csv
+//	        lint: redirects
+//	        csv:
+//	          delimiter: ";"
+//	          header: true
+//	      - name: flags
+//	        path: ./flags.json
+//	        overlays: [./flags.production.json]
+//	        rules: ./flags-rules.yaml
+type Manifest struct {
+	Services []ManifestService `yaml:"services"`
+}
+
+// ManifestService is a Fastly service and the dictionaries to sync for it
+type ManifestService struct {
+	Name         string               `yaml:"name"`
+	Dictionaries []ManifestDictionary `yaml:"dictionaries"`
+}
+
+// ManifestDictionary maps a Fastly dictionary name to a local file.
+// FileType is optional and is detected from the Path if not supplied.
+// Rules is an optional path to a rules file (see Rules).
+// Lint optionally checks the items before they are synced (redirects).
+// Scope is an optional key prefix or pattern owned by the sync (see WithKeyScope).
+// CSV is the optional dialect of a CSV file (see CSVDialect).
+// Overlays are optional files merged, in order, over the Path (see NewLayeredReader).
+type ManifestDictionary struct {
+	Name     string     `yaml:"name"`
+	Path     string     `yaml:"path"`
+	Overlays []string   `yaml:"overlays"`
+	FileType string     `yaml:"file-type"`
+	Rules    string     `yaml:"rules"`
+	Lint     string     `yaml:"lint"`
+	Scope    string     `yaml:"scope"`
+	CSV      CSVDialect `yaml:"csv"`
+}
+
+// Paths returns the Path followed by any Overlays
+func (d ManifestDictionary) Paths() []string {
+	return append([]string{d.Path}, d.Overlays...)
+}
+
+// LoadManifest reads and validates a manifest file.
+// Relative paths are resolved against the directory containing the manifest.
+func LoadManifest(path string) (*Manifest, error) {
+
+	b, err := os.ReadFile(path) // nolint : gosec 'path' is passed in via the user
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening manifest")
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+
+	manifest := &Manifest{}
+
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "error decoding manifest")
+	}
+
+	if err := manifest.validate(); err != nil {
+		return nil, err
+	}
+
+	base := filepath.Dir(path)
+
+	for i := range manifest.Services {
+		for j := range manifest.Services[i].Dictionaries {
+			d := &manifest.Services[i].Dictionaries[j]
+			if !filepath.IsAbs(d.Path) {
+				d.Path = filepath.Join(base, d.Path)
+			}
+			for k := range d.Overlays {
+				if !filepath.IsAbs(d.Overlays[k]) {
+					d.Overlays[k] = filepath.Join(base, d.Overlays[k])
+				}
+			}
+			if d.Rules != "" && !filepath.IsAbs(d.Rules) {
+				d.Rules = filepath.Join(base, d.Rules)
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+func (m *Manifest) validate() error {
+
+	if len(m.Services) == 0 {
+		return errors.New("manifest contains no services")
+	}
+
+	seen := map[string]bool{}
+
+	for _, s := range m.Services {
+
+		if s.Name == "" {
+			return errors.New("manifest service is missing a name")
+		}
+
+		for _, d := range s.Dictionaries {
+
+			if d.Name == "" || d.Path == "" {
+				return fmt.Errorf("manifest dictionary for service %s requires a name and a path", s.Name)
+			}
+
+			id := s.Name + "/" + d.Name
+			if seen[id] {
+				return fmt.Errorf("manifest contains %s more than once", id)
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
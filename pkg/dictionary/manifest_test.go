@@ -0,0 +1,65 @@
+package dictionary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func Test_LoadManifest(t *testing.T) {
+
+	path := writeManifest(t, `
+services:
+  - name: service-one
+    dictionaries:
+      - name: redirects
+        path: ./redirects.csv
+        csv:
+          delimiter: ";"
+          header: true
+      - name: flags
+        path: /abs/flags.json
+        overlays: [./flags.production.json]
+        file-type: json
+        rules: ./flags-rules.yaml
+`)
+
+	manifest, err := LoadManifest(path)
+
+	require.Nil(t, err)
+	require.Len(t, manifest.Services, 1)
+	require.Equal(t, "service-one", manifest.Services[0].Name)
+	require.Equal(t, []ManifestDictionary{
+		{Name: "redirects", Path: filepath.Join(filepath.Dir(path), "redirects.csv"), CSV: CSVDialect{Delimiter: ";", Header: true}},
+		{Name: "flags", Path: "/abs/flags.json", Overlays: []string{filepath.Join(filepath.Dir(path), "flags.production.json")}, FileType: "json", Rules: filepath.Join(filepath.Dir(path), "flags-rules.yaml")},
+	}, manifest.Services[0].Dictionaries)
+}
+
+func Test_LoadManifestInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: "services: []"},
+		{name: "unknown field", content: "services:\n  - name: foo\n    dictionary: []\n"},
+		{name: "missing service name", content: "services:\n  - dictionaries: []\n"},
+		{name: "missing path", content: "services:\n  - name: foo\n    dictionaries:\n      - name: bar\n"},
+		{name: "duplicate", content: "services:\n  - name: foo\n    dictionaries:\n      - name: bar\n        path: a.csv\n      - name: bar\n        path: b.csv\n"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadManifest(writeManifest(t, tc.content))
+			require.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package dictionary
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FileType is a supported format for local dictionary files
+type FileType string
+
+const (
+	// CSV files are of the format KEY,VALUE
+	CSV FileType = "csv"
+	// JSON files contain a single object of keys to values
+	JSON FileType = "json"
+	// YAML files contain a single map of keys to values
+	YAML FileType = "yaml"
+	// DotEnv files contain KEY=VALUE lines
+	DotEnv FileType = "env"
+)
+
+// ParseFileType returns the FileType for a user supplied string or an error
+func ParseFileType(str string) (FileType, error) {
+
+	switch strings.ToLower(str) {
+	case "csv":
+		return CSV, nil
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "env", "dotenv":
+		return DotEnv, nil
+	}
+	return "", fmt.Errorf("unsupported file type : %s", str)
+}
+
+// FileTypeFromPath detects the FileType from the extension of a path or returns an error
+func FileTypeFromPath(path string) (FileType, error) {
+
+	base := filepath.Base(path)
+
+	// .env, .env.production etc.
+	if strings.HasPrefix(base, ".env") {
+		return DotEnv, nil
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(base), ".")
+
+	if ext == "" {
+		return "", fmt.Errorf("cannot detect file type : %s", path)
+	}
+
+	return ParseFileType(ext)
+}
+
+// NewReader returns a local dictionary provider for the FileType
+func NewReader(fileType FileType, r io.Reader) (localReader, error) { // nolint
+
+	switch fileType {
+	case CSV:
+		return NewCSVReader(r, CSVDialect{})
+	case JSON:
+		return NewJSONReader(r), nil
+	case YAML:
+		return NewYAMLReader(r), nil
+	case DotEnv:
+		return NewDotEnvReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported file type : %s", fileType)
+}
+
+type jsonReader struct {
+	r     io.Reader
+	lines map[string]int
+}
+
+// NewJSONReader returns a local dictionary provider for a JSON object of keys to values
+func NewJSONReader(r io.Reader) *jsonReader { // nolint
+	return &jsonReader{r: r}
+}
+
+// ReadAll returns all of the key, value pairs sorted by key
+func (j *jsonReader) ReadAll() ([][]string, error) {
+
+	b, err := io.ReadAll(j.r)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading json")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	m := map[string]interface{}{}
+
+	if err := decoder.Decode(&m); err != nil {
+		return nil, errors.Wrap(err, "error decoding json")
+	}
+
+	j.lines = jsonKeyLines(b)
+	return scalarMapToRecords(m)
+}
+
+// Source returns the line the key was read from
+func (j *jsonReader) Source(key string) (string, int) {
+	return "", j.lines[key]
+}
+
+// jsonKeyLines returns the line of each key of a JSON object that has already been decoded
+func jsonKeyLines(b []byte) map[string]int {
+
+	lines := map[string]int{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+
+	if _, err := decoder.Token(); err != nil {
+		return lines
+	}
+
+	for decoder.More() {
+
+		token, err := decoder.Token()
+
+		if err != nil {
+			return lines
+		}
+
+		key, _ := token.(string)
+		// the offset is just after the key so on its line
+		lines[key] = 1 + bytes.Count(b[:decoder.InputOffset()], []byte("\n"))
+
+		var value json.RawMessage
+
+		if err := decoder.Decode(&value); err != nil {
+			return lines
+		}
+	}
+
+	return lines
+}
+
+type yamlReader struct {
+	r     io.Reader
+	lines map[string]int
+}
+
+// NewYAMLReader returns a local dictionary provider for a YAML map of keys to values
+func NewYAMLReader(r io.Reader) *yamlReader { // nolint
+	return &yamlReader{r: r}
+}
+
+// ReadAll returns all of the key, value pairs sorted by key
+func (y *yamlReader) ReadAll() ([][]string, error) {
+
+	m := map[string]interface{}{}
+	y.lines = map[string]int{}
+
+	document := yaml.Node{}
+	err := yaml.NewDecoder(y.r).Decode(&document)
+
+	// an empty document is an empty dictionary
+	if errors.Is(err, io.EOF) {
+		return scalarMapToRecords(m)
+	}
+
+	if err == nil {
+		err = document.Decode(&m)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding yaml")
+	}
+
+	if len(document.Content) == 1 && document.Content[0].Kind == yaml.MappingNode {
+		content := document.Content[0].Content
+		for i := 0; i+1 < len(content); i += 2 {
+			y.lines[content[i].Value] = content[i].Line
+		}
+	}
+
+	return scalarMapToRecords(m)
+}
+
+// Source returns the line the key was read from
+func (y *yamlReader) Source(key string) (string, int) {
+	return "", y.lines[key]
+}
+
+type dotEnvReader struct {
+	r     io.Reader
+	lines map[string]int
+}
+
+// NewDotEnvReader returns a local dictionary provider for KEY=VALUE lines.
+// Blank lines, lines starting with '#' and an 'export ' prefix are ignored.
+func NewDotEnvReader(r io.Reader) *dotEnvReader { // nolint
+	return &dotEnvReader{r: r}
+}
+
+// ReadAll returns all of the key, value pairs in file order
+func (d *dotEnvReader) ReadAll() ([][]string, error) {
+
+	records := [][]string{}
+	scanner := bufio.NewScanner(d.r)
+	line := 0
+	d.lines = map[string]int{}
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+
+		i := strings.Index(text, "=")
+
+		if i < 1 {
+			return nil, fmt.Errorf("invalid line %d : expected KEY=VALUE", line)
+		}
+
+		key := strings.TrimSpace(text[:i])
+		value, err := dotEnvValue(strings.TrimSpace(text[i+1:]))
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid line %d", line)
+		}
+
+		records = append(records, []string{key, value})
+		d.lines[key] = line
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error reading env file")
+	}
+
+	return records, nil
+}
+
+// Source returns the line the key was read from
+func (d *dotEnvReader) Source(key string) (string, int) {
+	return "", d.lines[key]
+}
+
+func dotEnvValue(raw string) (string, error) {
+
+	if raw == "" {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return "", errors.New("unterminated quoted value")
+		}
+		return raw[1 : len(raw)-1], nil
+	case '"':
+		if len(raw) < 2 || raw[len(raw)-1] != '"' {
+			return "", errors.New("unterminated quoted value")
+		}
+		replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+		return replacer.Replace(raw[1 : len(raw)-1]), nil
+	}
+
+	// strip any trailing comment from unquoted values
+	if i := strings.Index(raw, " #"); i > -1 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+
+	return raw, nil
+}
+
+func scalarMapToRecords(m map[string]interface{}) ([][]string, error) {
+
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	records := make([][]string, 0, len(keys))
+
+	for _, k := range keys {
+
+		switch v := m[k].(type) {
+		case string:
+			records = append(records, []string{k, v})
+		case json.Number, bool, int, int64, uint64, float64:
+			records = append(records, []string{k, fmt.Sprint(v)})
+		default:
+			return nil, fmt.Errorf("unsupported value for key %s : values must be strings, numbers or booleans", k)
+		}
+	}
+
+	return records, nil
+}
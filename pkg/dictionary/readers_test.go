@@ -0,0 +1,176 @@
+package dictionary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FileTypeFromPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected FileType
+		err      bool
+	}{
+		{path: "foo.csv", expected: CSV},
+		{path: "/tmp/foo.JSON", expected: JSON},
+		{path: "foo.yml", expected: YAML},
+		{path: "foo.yaml", expected: YAML},
+		{path: "foo.env", expected: DotEnv},
+		{path: "config/.env", expected: DotEnv},
+		{path: "config/.env.production", expected: DotEnv},
+		{path: "foo", err: true},
+		{path: "foo.txt", err: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.path, func(t *testing.T) {
+			ft, err := FileTypeFromPath(tc.path)
+
+			if tc.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, ft)
+		})
+	}
+}
+
+func Test_Readers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileType FileType
+		content  string
+		expected [][]string
+		err      bool
+	}{
+		{
+			name:     "csv",
+			fileType: CSV,
+			content:  "one-key,one-value\ntwo-key,two-value\n",
+			expected: [][]string{{"one-key", "one-value"}, {"two-key", "two-value"}},
+		},
+		{
+			name:     "json",
+			fileType: JSON,
+			content:  `{"two-key": "two-value", "one-key": "one-value", "number": 10.50, "flag": true}`,
+			expected: [][]string{{"flag", "true"}, {"number", "10.50"}, {"one-key", "one-value"}, {"two-key", "two-value"}},
+		},
+		{
+			name:     "json nested values fail",
+			fileType: JSON,
+			content:  `{"one-key": {"foo": "bar"}}`,
+			err:      true,
+		},
+		{
+			name:     "yaml",
+			fileType: YAML,
+			content:  "two-key: two-value\none-key: one-value\nflag: false\n",
+			expected: [][]string{{"flag", "false"}, {"one-key", "one-value"}, {"two-key", "two-value"}},
+		},
+		{
+			name:     "empty yaml",
+			fileType: YAML,
+			content:  "",
+			expected: [][]string{},
+		},
+		{
+			name:     "yaml lists fail",
+			fileType: YAML,
+			content:  "one-key:\n  - foo\n",
+			err:      true,
+		},
+		{
+			name:     "dotenv",
+			fileType: DotEnv,
+			content:  "# comment\n\nONE=one-value\nexport TWO=\"two \\\"value\\\"\"\nTHREE='three # value'\nFOUR=four # comment\nFIVE=\n",
+			expected: [][]string{{"ONE", "one-value"}, {"TWO", `two "value"`}, {"THREE", "three # value"}, {"FOUR", "four"}, {"FIVE", ""}},
+		},
+		{
+			name:     "dotenv missing separator fails",
+			fileType: DotEnv,
+			content:  "ONE\n",
+			err:      true,
+		},
+		{
+			name:     "dotenv unterminated quote fails",
+			fileType: DotEnv,
+			content:  "ONE=\"one\n",
+			err:      true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			reader, err := NewReader(tc.fileType, strings.NewReader(tc.content))
+			require.Nil(t, err)
+
+			records, err := reader.ReadAll()
+
+			if tc.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func Test_ReadersSourceLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileType FileType
+		content  string
+		expected map[string]int
+	}{
+		{
+			name:     "csv",
+			fileType: CSV,
+			content:  "one-key,one-value\n\ntwo-key,\"two\nvalue\"\nthree-key,three-value\n",
+			expected: map[string]int{"one-key": 1, "two-key": 3, "three-key": 5},
+		},
+		{
+			name:     "json",
+			fileType: JSON,
+			content:  "{\n  \"two-key\": \"two-value\",\n\n  \"one-key\": \"one-value\"\n}",
+			expected: map[string]int{"two-key": 2, "one-key": 4},
+		},
+		{
+			name:     "yaml",
+			fileType: YAML,
+			content:  "# comment\ntwo-key: two-value\none-key: one-value\n",
+			expected: map[string]int{"two-key": 2, "one-key": 3},
+		},
+		{
+			name:     "dotenv",
+			fileType: DotEnv,
+			content:  "# comment\n\nONE=one-value\nexport TWO=two-value\n",
+			expected: map[string]int{"ONE": 3, "TWO": 4},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			reader, err := NewReader(tc.fileType, strings.NewReader(tc.content))
+			require.Nil(t, err)
+
+			_, err = reader.ReadAll()
+			require.Nil(t, err)
+
+			sources, ok := reader.(sourceReader)
+			require.True(t, ok)
+
+			for key, line := range tc.expected {
+				file, actual := sources.Source(key)
+				require.Equal(t, "", file)
+				require.Equal(t, line, actual, key)
+			}
+		})
+	}
+}
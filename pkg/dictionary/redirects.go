@@ -0,0 +1,294 @@
+package dictionary
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Kinds of problem found in a dictionary of redirects
+const (
+	SelfRedirect    = "self-redirect"
+	RedirectCycle   = "cycle"
+	RedirectChain   = "chain"
+	UnnormalizedKey = "unnormalized-key"
+	InvalidURL      = "invalid-url"
+)
+
+// RedirectOptions configures the checks made on a dictionary mapping paths to redirect URLs.
+// MaxHops is the longest chain of redirects allowed, zero allowing any length.
+// Relative URLs are followed to the next redirect as are absolute URLs to one of the Hosts.
+type RedirectOptions struct {
+	MaxHops int
+	Hosts   []string
+}
+
+// WithRedirectLint allows the local items to be checked as redirects before any
+// change is planned. A nil options disables the check.
+func WithRedirectLint(options *RedirectOptions) option {
+	return func(m *manager) {
+		m.redirects = options
+	}
+}
+
+// RedirectProblem is a redirect that is invalid or is a cycle or chain of redirects
+type RedirectProblem struct {
+	Key    string `json:"key"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// LintRedirects returns the problems, sorted by key, found in items mapping paths to
+// redirect URLs. Keys should be normalized paths and values absolute http(s) URLs or paths.
+// Cycles are reported once against their first key and chains against the key starting them.
+func LintRedirects(items map[string]string, options RedirectOptions) []RedirectProblem {
+
+	problems := []RedirectProblem{}
+	next := map[string]string{}
+
+	for _, key := range sortedKeys(items) {
+
+		if reason := checkRedirectKey(key); reason != "" {
+			problems = append(problems, RedirectProblem{Key: key, Kind: UnnormalizedKey, Detail: reason})
+		}
+
+		target, err := options.target(items[key])
+
+		if err != nil {
+			problems = append(problems, RedirectProblem{Key: key, Kind: InvalidURL, Detail: err.Error()})
+			continue
+		}
+
+		if target == key {
+			problems = append(problems, RedirectProblem{Key: key, Kind: SelfRedirect, Detail: fmt.Sprintf("redirects to itself : %s", items[key])})
+			continue
+		}
+
+		if _, found := items[target]; found && target != "" {
+			next[key] = target
+		}
+	}
+
+	cycles := findCycles(next)
+	problems = append(problems, cycles...)
+
+	inCycle := map[string]bool{}
+	for _, c := range cycles {
+		for key := next[c.Key]; key != c.Key; key = next[key] {
+			inCycle[key] = true
+		}
+		inCycle[c.Key] = true
+	}
+
+	if options.MaxHops > 0 {
+		problems = append(problems, findChains(items, next, inCycle, options.MaxHops)...)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Key < problems[j].Key
+	})
+	return problems
+}
+
+// checkRedirectKey returns why the key is not a normalized path or an empty string
+func checkRedirectKey(key string) string {
+
+	if !strings.HasPrefix(key, "/") {
+		return "key is not a path starting with /"
+	}
+
+	u, err := url.Parse(key)
+
+	if err != nil {
+		return fmt.Sprintf("key is not a valid path : %s", err)
+	}
+
+	if u.Host != "" || u.RawQuery != "" || u.Fragment != "" || u.ForceQuery {
+		return "key is not a path"
+	}
+
+	if clean := path.Clean(u.Path); clean != u.Path {
+		return fmt.Sprintf("key should be %s", clean)
+	}
+
+	if u.EscapedPath() != key {
+		return fmt.Sprintf("key should be escaped as %s", u.EscapedPath())
+	}
+
+	return ""
+}
+
+// target returns the path the redirect leads to within the dictionary, an empty string
+// if it leaves for another host, or an error if the value is not a valid URL
+func (o RedirectOptions) target(value string) (string, error) {
+
+	u, err := url.Parse(value)
+
+	if err != nil {
+		return "", fmt.Errorf("value is not a valid URL : %s", err)
+	}
+
+	switch {
+	case u.Scheme == "" && u.Host == "":
+		if !strings.HasPrefix(u.Path, "/") {
+			return "", fmt.Errorf("value is not an absolute URL or a path starting with /")
+		}
+		return u.Path, nil
+	case u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https":
+		return "", fmt.Errorf("value has unsupported scheme %s", u.Scheme)
+	case u.Host == "":
+		return "", fmt.Errorf("value is missing a host")
+	}
+
+	for _, host := range o.Hosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			if u.Path == "" {
+				return "/", nil
+			}
+			return u.Path, nil
+		}
+	}
+	return "", nil
+}
+
+// findCycles returns a problem for each cycle of redirects
+func findCycles(next map[string]string) []RedirectProblem {
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	problems := []RedirectProblem{}
+	state := map[string]int{}
+
+	for _, start := range sortedKeys(next) {
+
+		walk := []string{}
+		key := start
+		found := true
+
+		for found && state[key] == unvisited {
+
+			state[key] = visiting
+			walk = append(walk, key)
+
+			key, found = next[key]
+		}
+
+		if found && state[key] == visiting {
+
+			// the walk has returned to a key on it so the cycle is the walk from that key
+			cycle := []string{}
+			for i := len(walk) - 1; walk[i] != key; i-- {
+				cycle = append([]string{walk[i]}, cycle...)
+			}
+			cycle = append([]string{key}, cycle...)
+
+			first := 0
+			for i := range cycle {
+				if cycle[i] < cycle[first] {
+					first = i
+				}
+			}
+			cycle = append(cycle[first:], cycle[:first]...)
+
+			problems = append(problems, RedirectProblem{
+				Key:    cycle[0],
+				Kind:   RedirectCycle,
+				Detail: strings.Join(append(cycle, cycle[0]), " -> "),
+			})
+		}
+
+		for _, k := range walk {
+			state[k] = visited
+		}
+	}
+
+	return problems
+}
+
+// findChains returns a problem for each key starting a chain longer than maxHops.
+// Chains leading into a cycle are not reported as the cycle is.
+func findChains(items, next map[string]string, inCycle map[string]bool, maxHops int) []RedirectProblem {
+
+	targeted := map[string]bool{}
+	for _, target := range next {
+		targeted[target] = true
+	}
+
+	problems := []RedirectProblem{}
+
+	for _, start := range sortedKeys(next) {
+
+		if targeted[start] {
+			continue
+		}
+
+		chain := []string{start}
+		key := start
+		cyclic := false
+
+		for {
+			target, found := next[key]
+
+			if !found {
+				break
+			}
+
+			if inCycle[target] {
+				cyclic = true
+				break
+			}
+
+			chain = append(chain, target)
+			key = target
+		}
+
+		if cyclic || len(chain) <= maxHops {
+			continue
+		}
+
+		problems = append(problems, RedirectProblem{
+			Key:    start,
+			Kind:   RedirectChain,
+			Detail: fmt.Sprintf("%d hops : %s -> %s", len(chain), strings.Join(chain, " -> "), items[key]),
+		})
+	}
+
+	return problems
+}
+
+// lintRedirects returns ErrRedirectProblems if redirect linting is enabled
+// and the local items contain any problems
+func (m *manager) lintRedirects(items map[string]string) error {
+
+	if m.redirects == nil {
+		return nil
+	}
+
+	if problems := LintRedirects(items, *m.redirects); len(problems) > 0 {
+		return &ErrRedirectProblems{Problems: problems}
+	}
+	return nil
+}
+
+// ErrRedirectProblems signals the local items contain invalid redirects
+type ErrRedirectProblems struct {
+	Problems []RedirectProblem
+}
+
+func (e *ErrRedirectProblems) Error() string {
+
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("%d redirect problems :", len(e.Problems)))
+
+	for _, p := range e.Problems {
+		lines = append(lines, fmt.Sprintf("  %s : %s : %s", p.Key, p.Kind, p.Detail))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,121 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LintRedirects(t *testing.T) {
+	testCases := []struct {
+		name     string
+		items    map[string]string
+		options  RedirectOptions
+		expected []RedirectProblem
+	}{
+		{
+			name:     "valid",
+			items:    map[string]string{"/old": "/new", "/other": "https://example.com/other"},
+			expected: []RedirectProblem{},
+		},
+		{
+			name:  "self redirect",
+			items: map[string]string{"/same": "/same"},
+			expected: []RedirectProblem{
+				{Key: "/same", Kind: SelfRedirect, Detail: "redirects to itself : /same"},
+			},
+		},
+		{
+			name:    "self redirect to a host",
+			items:   map[string]string{"/same": "https://www.example.com/same"},
+			options: RedirectOptions{Hosts: []string{"www.example.com"}},
+			expected: []RedirectProblem{
+				{Key: "/same", Kind: SelfRedirect, Detail: "redirects to itself : https://www.example.com/same"},
+			},
+		},
+		{
+			name:     "other hosts are not followed",
+			items:    map[string]string{"/same": "https://elsewhere.com/same"},
+			options:  RedirectOptions{Hosts: []string{"www.example.com"}},
+			expected: []RedirectProblem{},
+		},
+		{
+			name:  "cycle",
+			items: map[string]string{"/c": "/a", "/a": "/b", "/b": "/c", "/d": "/a"},
+			expected: []RedirectProblem{
+				{Key: "/a", Kind: RedirectCycle, Detail: "/a -> /b -> /c -> /a"},
+			},
+		},
+		{
+			name:    "chain",
+			items:   map[string]string{"/a": "/b", "/b": "/c", "/c": "https://example.com/d", "/x": "/c"},
+			options: RedirectOptions{MaxHops: 2},
+			expected: []RedirectProblem{
+				{Key: "/a", Kind: RedirectChain, Detail: "3 hops : /a -> /b -> /c -> https://example.com/d"},
+			},
+		},
+		{
+			name:     "chain within the limit",
+			items:    map[string]string{"/a": "/b", "/b": "/c"},
+			options:  RedirectOptions{MaxHops: 2},
+			expected: []RedirectProblem{},
+		},
+		{
+			name: "unnormalized keys",
+			items: map[string]string{
+				"old":           "/new",
+				"/trailing/":    "/new",
+				"/double//path": "/new",
+				"/query?a=b":    "/new",
+				"/with space":   "/new",
+			},
+			expected: []RedirectProblem{
+				{Key: "/double//path", Kind: UnnormalizedKey, Detail: "key should be /double/path"},
+				{Key: "/query?a=b", Kind: UnnormalizedKey, Detail: "key is not a path"},
+				{Key: "/trailing/", Kind: UnnormalizedKey, Detail: "key should be /trailing"},
+				{Key: "/with space", Kind: UnnormalizedKey, Detail: "key should be escaped as /with%20space"},
+				{Key: "old", Kind: UnnormalizedKey, Detail: "key is not a path starting with /"},
+			},
+		},
+		{
+			name: "invalid urls",
+			items: map[string]string{
+				"/relative": "new",
+				"/scheme":   "ftp://example.com/new",
+				"/host":     "https:///new",
+			},
+			expected: []RedirectProblem{
+				{Key: "/host", Kind: InvalidURL, Detail: "value is missing a host"},
+				{Key: "/relative", Kind: InvalidURL, Detail: "value is not an absolute URL or a path starting with /"},
+				{Key: "/scheme", Kind: InvalidURL, Detail: "value has unsupported scheme ftp"},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, LintRedirects(tc.items, tc.options))
+		})
+	}
+}
+
+func Test_PlanWithRedirectLint(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{}}
+
+	m := Manager(remote,
+		WithLocalReader(&mockLocalReader{reader: func() ([][]string, error) {
+			return [][]string{{"/a", "/b"}, {"/b", "/a"}}, nil
+		}}),
+		WithRedirectLint(&RedirectOptions{MaxHops: 1}),
+	)
+
+	_, err := m.Plan()
+
+	var problems *ErrRedirectProblems
+	require.True(t, errors.As(err, &problems))
+	require.Len(t, problems.Problems, 1)
+	require.Equal(t, RedirectCycle, problems.Problems[0].Kind)
+}
@@ -0,0 +1,48 @@
+package dictionary
+
+import (
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+)
+
+type remoteDictionaryLister interface {
+	ListDictionaryItems(*fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error)
+}
+
+type remoteReader struct {
+	client       remoteDictionaryLister
+	serviceID    string
+	dictionaryID string
+}
+
+// NewRemoteReader returns a local dictionary provider for the items of a remote dictionary
+// allowing one dictionary to be synced from another
+// NOTE : this that function requires IDs and NOT the name's of the entities
+func NewRemoteReader(client remoteDictionaryLister, serviceID, dictionaryID string) *remoteReader { // nolint
+	return &remoteReader{client: client, serviceID: serviceID, dictionaryID: dictionaryID}
+}
+
+// ReadAll returns all of the key, value pairs sorted by key
+func (r *remoteReader) ReadAll() ([][]string, error) {
+
+	items, err := r.client.ListDictionaryItems(&fastly.ListDictionaryItemsInput{
+		Service: r.serviceID, Dictionary: r.dictionaryID,
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving source dictionary items")
+	}
+
+	items = WithoutLock(items)
+	sort.Slice(items, func(i, j int) bool { return items[i].ItemKey < items[j].ItemKey })
+
+	records := make([][]string, 0, len(items))
+
+	for _, item := range items {
+		records = append(records, []string{item.ItemKey, item.ItemValue})
+	}
+
+	return records, nil
+}
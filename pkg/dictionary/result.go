@@ -0,0 +1,69 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fastly/go-fastly/fastly"
+)
+
+// Result is the outcome of applying changes to a remote dictionary.
+// Unchanged is only known when the changes were planned by the same manager.
+type Result struct {
+	Created   Keys          `json:"created"`
+	Updated   Keys          `json:"updated"`
+	Deleted   Keys          `json:"deleted"`
+	Unchanged Keys          `json:"unchanged"`
+	Batches   int           `json:"batches"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Keys are the keys of the items with the same outcome
+type Keys struct {
+	Count int      `json:"count"`
+	Keys  []string `json:"keys"`
+}
+
+func newResult() *Result {
+	return &Result{
+		Created:   Keys{Keys: []string{}},
+		Updated:   Keys{Keys: []string{}},
+		Deleted:   Keys{Keys: []string{}},
+		Unchanged: Keys{Keys: []string{}},
+	}
+}
+
+func (k *Keys) add(keys ...string) {
+	k.Count += len(keys)
+	k.Keys = append(k.Keys, keys...)
+}
+
+// record adds an applied batch of changes to the result
+func (r *Result) record(changes []Change) {
+
+	for _, c := range changes {
+		switch c.Operation {
+		case fastly.CreateBatchOperation:
+			r.Created.add(c.Key)
+		case fastly.UpdateBatchOperation:
+			r.Updated.add(c.Key)
+		case fastly.DeleteBatchOperation:
+			r.Deleted.add(c.Key)
+		}
+	}
+	r.Batches++
+}
+
+// MarshalJSON writes the duration in a human readable form e.g. 1.5s
+func (r Result) MarshalJSON() ([]byte, error) {
+
+	type result Result
+
+	return json.Marshal(struct {
+		result
+		Duration string `json:"duration"`
+	}{
+		result:   result(r),
+		Duration: r.Duration.String(),
+	})
+}
@@ -0,0 +1,74 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SyncResult(t *testing.T) {
+
+	remote := &mockRemoteDictionary{
+		items: map[string]string{"one-key": "one-value", "two-key": "two-value", "three-key": "three-value"},
+	}
+
+	local := &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return [][]string{{"one-key", "one-value"}, {"two-key", "new-value"}, {"four-key", "four-value"}}, nil
+		},
+	}
+
+	result, err := Manager(remote, WithLocalReader(local)).Sync()
+
+	require.Nil(t, err)
+	require.Equal(t, Keys{Count: 1, Keys: []string{"four-key"}}, result.Created)
+	require.Equal(t, Keys{Count: 1, Keys: []string{"two-key"}}, result.Updated)
+	require.Equal(t, Keys{Count: 1, Keys: []string{"three-key"}}, result.Deleted)
+	require.Equal(t, Keys{Count: 1, Keys: []string{"one-key"}}, result.Unchanged)
+	require.Equal(t, 1, result.Batches)
+}
+
+func Test_SyncResultPartial(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{}, failOn: 2}
+
+	records := [][]string{}
+	for i := 0; i < 1500; i++ {
+		records = append(records, []string{fmt.Sprintf("key-%04d", i), "value"})
+	}
+
+	local := &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return records, nil
+		},
+	}
+
+	result, err := Manager(remote, WithLocalReader(local)).Sync()
+
+	// only the first batch was applied
+	require.NotNil(t, err)
+	require.Equal(t, 1, result.Batches)
+	require.Equal(t, 1000, result.Created.Count)
+}
+
+func Test_ResultJSON(t *testing.T) {
+
+	result := newResult()
+	result.Created.add("one-key")
+	result.Duration = 1500 * time.Millisecond
+
+	b, err := json.Marshal(result)
+
+	require.Nil(t, err)
+	require.JSONEq(t, `{
+		"created": {"count": 1, "keys": ["one-key"]},
+		"updated": {"count": 0, "keys": []},
+		"deleted": {"count": 0, "keys": []},
+		"unchanged": {"count": 0, "keys": []},
+		"batches": 0,
+		"duration": "1.5s"
+	}`, string(b))
+}
@@ -0,0 +1,279 @@
+package dictionary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules constrain the values of local items by key.
+// Every rule matching a key is checked.
+//
+//	rules:
+//	  - key: redirect-*
+//	    url: true
+//	  - key-regex: ^flag-
+//	    enum: ["true", "false"]
+//	  - key: max-*
+//	    integer:
+//	      min: 0
+//	      max: 100
+//	  - key: backend-*
+//	    regex: ^(origin|shield)_[a-z]+$
+//	  - key: config
+//	    json: true
+type Rules struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule constrains the values of keys matching either the Key glob, in which * also
+// matches /, or the KeyRegex.
+// At least one constraint is required and values must satisfy all of them.
+type Rule struct {
+	Key      string        `yaml:"key"`
+	KeyRegex string        `yaml:"key-regex"`
+	Regex    string        `yaml:"regex"`
+	Enum     []string      `yaml:"enum"`
+	URL      bool          `yaml:"url"`
+	Integer  *IntegerRange `yaml:"integer"`
+	JSON     bool          `yaml:"json"`
+
+	keyRegex *regexp.Regexp
+	regex    *regexp.Regexp
+}
+
+// IntegerRange constrains values to integers between the optional inclusive bounds
+type IntegerRange struct {
+	Min *int64 `yaml:"min"`
+	Max *int64 `yaml:"max"`
+}
+
+// LoadRules reads, validates and compiles a rules file
+func LoadRules(path string) (*Rules, error) {
+
+	b, err := os.ReadFile(path) // nolint : gosec 'path' is passed in via the user
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening rules")
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+
+	rules := &Rules{}
+
+	if err := decoder.Decode(rules); err != nil {
+		return nil, errors.Wrap(err, "error decoding rules")
+	}
+
+	for i := range rules.Rules {
+		if err := rules.Rules[i].compile(); err != nil {
+			return nil, errors.Wrapf(err, "invalid rule %d", i+1)
+		}
+	}
+
+	return rules, nil
+}
+
+// WithRules allows specifying rules that every local item is checked against
+// before the remote dictionary is read. A nil Rules disables the checks.
+func WithRules(rules *Rules) option {
+	return func(m *manager) {
+		m.rules = rules
+	}
+}
+
+func (r *Rule) compile() error {
+
+	var err error
+
+	switch {
+	case r.Key != "" && r.KeyRegex != "":
+		return errors.New("key and key-regex cannot both be set")
+	case r.Key != "":
+		if _, err = matchKey(r.Key, ""); err != nil {
+			return errors.Wrapf(err, "invalid key pattern %s", r.Key)
+		}
+	case r.KeyRegex != "":
+		if r.keyRegex, err = regexp.Compile(r.KeyRegex); err != nil {
+			return errors.Wrapf(err, "invalid key-regex %s", r.KeyRegex)
+		}
+	default:
+		return errors.New("key or key-regex is required")
+	}
+
+	if r.Regex != "" {
+		if r.regex, err = regexp.Compile(r.Regex); err != nil {
+			return errors.Wrapf(err, "invalid regex %s", r.Regex)
+		}
+	}
+
+	if r.Regex == "" && len(r.Enum) == 0 && !r.URL && r.Integer == nil && !r.JSON {
+		return errors.New("at least one of regex, enum, url, integer or json is required")
+	}
+
+	if r.Integer != nil && r.Integer.Min != nil && r.Integer.Max != nil && *r.Integer.Min > *r.Integer.Max {
+		return errors.New("integer min is greater than max")
+	}
+
+	return nil
+}
+
+func (r *Rule) matches(key string) bool {
+
+	if r.keyRegex != nil {
+		return r.keyRegex.MatchString(key)
+	}
+
+	// the pattern was validated when the rule was compiled
+	matched, _ := matchKey(r.Key, key)
+	return matched
+}
+
+// check returns the reasons the value breaks the rule
+func (r *Rule) check(value string) []string {
+
+	reasons := []string{}
+
+	if r.regex != nil && !r.regex.MatchString(value) {
+		reasons = append(reasons, fmt.Sprintf("value does not match %s", r.Regex))
+	}
+
+	if len(r.Enum) > 0 && !contains(r.Enum, value) {
+		reasons = append(reasons, fmt.Sprintf("value is not one of %s", strings.Join(r.Enum, ", ")))
+	}
+
+	if r.URL {
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			reasons = append(reasons, "value is not an absolute URL")
+		}
+	}
+
+	if r.Integer != nil {
+		reasons = append(reasons, r.Integer.check(value)...)
+	}
+
+	if r.JSON && !json.Valid([]byte(value)) {
+		reasons = append(reasons, "value is not valid JSON")
+	}
+
+	return reasons
+}
+
+func (i *IntegerRange) check(value string) []string {
+
+	n, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return []string{"value is not an integer"}
+	}
+
+	if i.Min != nil && n < *i.Min {
+		return []string{fmt.Sprintf("value is less than %d", *i.Min)}
+	}
+
+	if i.Max != nil && n > *i.Max {
+		return []string{fmt.Sprintf("value is greater than %d", *i.Max)}
+	}
+	return nil
+}
+
+// check returns ErrRuleViolations listing every item breaking a rule with
+// the file and line, returned by source, the item was read from
+func (r *Rules) check(records [][]string, source func(key string) (string, int)) error {
+
+	if r == nil {
+		return nil
+	}
+
+	violations := []RuleViolation{}
+
+	for _, record := range records {
+
+		if len(record) < 2 {
+			continue
+		}
+
+		key, value := record[0], record[1]
+
+		for j := range r.Rules {
+
+			if !r.Rules[j].matches(key) {
+				continue
+			}
+
+			for _, reason := range r.Rules[j].check(value) {
+				file, line := source(key)
+				violations = append(violations, RuleViolation{File: file, Line: line, Key: key, Reason: reason})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ErrRuleViolations{Violations: violations}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleViolation is a local item, identified by its key, that breaks a rule.
+// File and Line are where the item was read from and are empty if not known.
+type RuleViolation struct {
+	File   string
+	Line   int
+	Key    string
+	Reason string
+}
+
+// location returns where the item was read from e.g. prod.csv:4
+func (v RuleViolation) location() string {
+
+	switch {
+	case v.File != "" && v.Line > 0:
+		return fmt.Sprintf("%s:%d", v.File, v.Line)
+	case v.File != "":
+		return v.File
+	case v.Line > 0:
+		return fmt.Sprintf("line %d", v.Line)
+	}
+	return ""
+}
+
+// ErrRuleViolations signals local items break the validation rules.
+// Values are not included as they may contain substituted secrets.
+type ErrRuleViolations struct {
+	Violations []RuleViolation
+}
+
+func (e *ErrRuleViolations) Error() string {
+
+	lines := make([]string, 0, len(e.Violations)+1)
+	lines = append(lines, fmt.Sprintf("%d rule violations :", len(e.Violations)))
+
+	for _, v := range e.Violations {
+		if location := v.location(); location != "" {
+			lines = append(lines, fmt.Sprintf("  %s : %s : %s", location, v.Key, v.Reason))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s : %s", v.Key, v.Reason))
+	}
+
+	return strings.Join(lines, "\n")
+}
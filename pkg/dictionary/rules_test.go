@@ -0,0 +1,163 @@
+package dictionary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+rules:
+  - key: redirect-*
+    url: true
+  - key-regex: ^flag-
+    enum: ["true", "false"]
+  - key: max-*
+    integer:
+      min: 0
+      max: 100
+  - key: backend-*
+    regex: ^origin_[a-z]+$
+  - key: config
+    json: true
+`
+
+func writeRules(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func Test_Rules(t *testing.T) {
+
+	rules, err := LoadRules(writeRules(t, testRules))
+	require.Nil(t, err)
+
+	testCases := []struct {
+		name       string
+		local      [][]string
+		violations []RuleViolation
+	}{
+		{
+			name: "valid",
+			local: [][]string{
+				{"redirect-home", "https://example.com/home"},
+				{"flag-beta", "true"},
+				{"max-retries", "100"},
+				{"backend-eu", "origin_eu"},
+				{"config", `{"a":1}`},
+				{"unmatched", "anything"},
+			},
+		},
+		{
+			name: "every violation is reported",
+			local: [][]string{
+				{"redirect-home", "/home"},
+				{"flag-beta", "yes"},
+				{"max-retries", "101"},
+				{"max-age", "forever"},
+				{"backend-eu", "shield_eu"},
+				{"config", `{"a":`},
+			},
+			violations: []RuleViolation{
+				{Key: "redirect-home", Reason: "value is not an absolute URL"},
+				{Key: "flag-beta", Reason: "value is not one of true, false"},
+				{Key: "max-retries", Reason: "value is greater than 100"},
+				{Key: "max-age", Reason: "value is not an integer"},
+				{Key: "backend-eu", Reason: "value does not match ^origin_[a-z]+$"},
+				{Key: "config", Reason: "value is not valid JSON"},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			remoteCalled := false
+
+			client := &mockRemoteSource{
+				itemLister: func(i *fastly.ListDictionaryItemsInput) ([]*fastly.DictionaryItem, error) {
+					remoteCalled = true
+					return []*fastly.DictionaryItem{}, nil
+				},
+			}
+
+			local := &mockLocalReader{
+				reader: func() ([][]string, error) {
+					return tc.local, nil
+				},
+			}
+
+			m := Manager(client, WithLocalReader(local), WithRules(rules))
+
+			// the local items can be checked without reading the remote dictionary
+			checkErr := m.Check()
+			require.False(t, remoteCalled)
+
+			_, err := m.Plan()
+			require.Equal(t, checkErr, err)
+
+			if tc.violations == nil {
+				require.Nil(t, err)
+				return
+			}
+
+			var violations *ErrRuleViolations
+			require.True(t, errors.As(err, &violations))
+			require.Equal(t, tc.violations, violations.Violations)
+
+			// the rules are checked before the remote dictionary is read
+			require.False(t, remoteCalled)
+		})
+	}
+}
+
+func Test_LoadRulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: "rules:\n  - key: a\n    urls: true\n"},
+		{name: "missing key", content: "rules:\n  - url: true\n"},
+		{name: "key and key-regex", content: "rules:\n  - key: a\n    key-regex: a\n    url: true\n"},
+		{name: "invalid key-regex", content: "rules:\n  - key-regex: '['\n    url: true\n"},
+		{name: "invalid regex", content: "rules:\n  - key: a\n    regex: '['\n"},
+		{name: "no constraints", content: "rules:\n  - key: a\n"},
+		{name: "min greater than max", content: "rules:\n  - key: a\n    integer:\n      min: 2\n      max: 1\n"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadRules(writeRules(t, tc.content))
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func Test_RuleViolationsListSource(t *testing.T) {
+
+	rules, err := LoadRules(writeRules(t, testRules))
+	require.Nil(t, err)
+
+	m := Manager(&mockRemoteDictionary{}, WithRules(rules), WithLocalReader(NewLayeredReader(
+		csvLayer(t, "base.csv", "redirect-home,https://example.com\nflag-beta,true\nmax-age,forever\n"),
+		csvLayer(t, "prod.csv", "flag-beta,false\nredirect-home,/home\n"),
+	)))
+
+	err = m.Check()
+
+	var violations *ErrRuleViolations
+	require.True(t, errors.As(err, &violations))
+	require.Equal(t, []RuleViolation{
+		{File: "prod.csv", Line: 2, Key: "redirect-home", Reason: "value is not an absolute URL"},
+		{File: "base.csv", Line: 3, Key: "max-age", Reason: "value is not an integer"},
+	}, violations.Violations)
+
+	require.Contains(t, err.Error(), "prod.csv:2 : redirect-home : value is not an absolute URL")
+}
@@ -0,0 +1,150 @@
+package dictionary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
+	"github.com/pkg/errors"
+)
+
+const savedPlanVersion = 1
+
+var (
+	// ErrStalePlan signals the remote dictionary has changed since a saved plan was made
+	ErrStalePlan = errors.New("remote dictionary has changed since the plan was made")
+)
+
+// SavedPlan is a plan saved to be reviewed and applied later.
+// Fingerprint identifies the state of the remote items inside the Scope when the plan was made.
+// NOTE : the new values of Sensitive changes are saved unredacted so they can be applied.
+// The remote values they replace are not needed so are redacted.
+type SavedPlan struct {
+	Version      int        `json:"version"`
+	Created      time.Time  `json:"created"`
+	ServiceID    string     `json:"service_id"`
+	DictionaryID string     `json:"dictionary_id"`
+	Scope        string     `json:"scope,omitempty"`
+	Fingerprint  string     `json:"fingerprint"`
+	Batches      [][]Change `json:"batches"`
+}
+
+// SavePlan returns the changes returned by Plan as a SavedPlan or an error if no plan has been made
+func (m *manager) SavePlan(changes []Change) (*SavedPlan, error) {
+
+	if m.before == nil {
+		return nil, errors.New("cannot save a plan before one is made")
+	}
+
+	saved := make([]Change, 0, len(changes))
+
+	for _, c := range changes {
+		if c.From != "" && (c.Sensitive || c.RemoteSensitive) {
+			c.From = Redacted
+		}
+		saved = append(saved, c)
+	}
+
+	return &SavedPlan{
+		Version:      savedPlanVersion,
+		Created:      time.Now().UTC(),
+		ServiceID:    m.serviceID,
+		DictionaryID: m.dictionaryID,
+		Scope:        m.scope.scope,
+		Fingerprint:  fingerprint(m.scope.restrict(m.before)),
+		Batches:      batch(saved),
+	}, nil
+}
+
+// ApplyPlan applies the batches of a saved plan returning what changed or an error.
+// ErrStalePlan is returned, and nothing is changed, if the remote items inside the scope
+// of the plan have changed since it was made.
+func (m *manager) ApplyPlan(p *SavedPlan) (*Result, error) {
+
+	if p.ServiceID != m.serviceID || p.DictionaryID != m.dictionaryID {
+		return nil, fmt.Errorf("plan is for a different dictionary : %s/%s", p.ServiceID, p.DictionaryID)
+	}
+
+	m.scope = keyScope{scope: p.Scope}
+
+	if err := m.scope.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(); err != nil {
+		return nil, err
+	}
+
+	remoteItems, err := m.listRemote()
+
+	if err != nil {
+		m.Unlock() // nolint: errcheck
+		return nil, err
+	}
+
+	m.before = fastlyDictionaryItemsToMap(remoteItems)
+
+	if fingerprint(m.scope.restrict(m.before)) != p.Fingerprint {
+		m.Unlock() // nolint: errcheck
+		return nil, ErrStalePlan
+	}
+
+	changes := []Change{}
+	for _, b := range p.Batches {
+		changes = append(changes, b...)
+	}
+
+	return m.apply(audit.DictionarySync, changes)
+}
+
+// fingerprint returns a hash of the items
+func fingerprint(items map[string]string) string {
+
+	h := sha256.New()
+
+	for _, k := range sortedKeys(items) {
+		// quoting keeps the boundaries between keys and values unambiguous
+		fmt.Fprintf(h, "%q=%q\n", k, items[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Save writes the plan to the file at path. The file is only readable by the
+// current user as it may contain sensitive values.
+func (p *SavedPlan) Save(path string) error {
+
+	b, err := json.MarshalIndent(p, "", "  ")
+
+	if err != nil {
+		return errors.Wrap(err, "error encoding plan")
+	}
+
+	return errors.Wrap(os.WriteFile(path, b, 0600), "error writing plan")
+}
+
+// LoadPlan reads a plan saved to the file at path
+func LoadPlan(path string) (*SavedPlan, error) {
+
+	b, err := os.ReadFile(path) // nolint : gosec 'path' is passed in via the user
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading plan")
+	}
+
+	p := &SavedPlan{}
+
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, errors.Wrap(err, "error decoding plan")
+	}
+
+	if p.Version != savedPlanVersion {
+		return nil, fmt.Errorf("unsupported plan version : %d", p.Version)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,114 @@
+package dictionary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// savePlan plans syncing the local items with remote and saves the plan returning its path
+func savePlan(t *testing.T, remote *mockRemoteDictionary, scope string, records ...[]string) string {
+
+	m := Manager(remote,
+		WithLocalReader(localItems(records...)),
+		WithRemoteDictionary("service", "dictionary"),
+		WithKeyScope(scope),
+	)
+
+	changes, err := m.Plan()
+	require.Nil(t, err)
+
+	p, err := m.SavePlan(changes)
+	require.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.Nil(t, p.Save(path))
+
+	return path
+}
+
+func applyPlan(t *testing.T, remote *mockRemoteDictionary, path string) (*Result, error) {
+
+	p, err := LoadPlan(path)
+	require.Nil(t, err)
+
+	return Manager(remote, WithRemoteDictionary("service", "dictionary")).ApplyPlan(p)
+}
+
+func Test_SaveAndApplyPlan(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value", "two-key": "two-value"}}
+	path := savePlan(t, remote, "", []string{"one-key", "new-value"}, []string{"three-key", "three-value"})
+
+	// saving the plan makes no changes
+	require.Equal(t, 0, remote.batches)
+
+	result, err := applyPlan(t, remote, path)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"one-key": "new-value", "three-key": "three-value"}, remote.items)
+	require.Equal(t, 1, result.Created.Count)
+	require.Equal(t, 1, result.Updated.Count)
+	require.Equal(t, 1, result.Deleted.Count)
+}
+
+func Test_ApplyStalePlan(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"one-key": "one-value"}}
+	path := savePlan(t, remote, "", []string{"one-key", "new-value"})
+
+	remote.items["one-key"] = "changed-value"
+
+	_, err := applyPlan(t, remote, path)
+	require.Equal(t, ErrStalePlan, err)
+	require.Equal(t, map[string]string{"one-key": "changed-value"}, remote.items)
+	require.Equal(t, 0, remote.batches)
+}
+
+func Test_ApplyScopedPlan(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"team-a/one": "a", "team-b/one": "b"}}
+	path := savePlan(t, remote, "team-a/", []string{"team-a/one", "new"})
+
+	// changes outside of the scope do not invalidate the plan
+	remote.items["team-b/one"] = "changed"
+
+	_, err := applyPlan(t, remote, path)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"team-a/one": "new", "team-b/one": "changed"}, remote.items)
+
+	path = savePlan(t, remote, "team-a/", []string{"team-a/one", "newer"})
+	remote.items["team-a/two"] = "added"
+
+	_, err = applyPlan(t, remote, path)
+	require.Equal(t, ErrStalePlan, err)
+}
+
+func Test_ApplyPlanForDifferentDictionary(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{}}
+	path := savePlan(t, remote, "", []string{"one-key", "one-value"})
+
+	p, err := LoadPlan(path)
+	require.Nil(t, err)
+
+	_, err = Manager(remote, WithRemoteDictionary("service", "other")).ApplyPlan(p)
+	require.NotNil(t, err)
+	require.Equal(t, map[string]string{}, remote.items)
+}
+
+func Test_SavePlanBeforePlan(t *testing.T) {
+
+	_, err := Manager(&mockRemoteDictionary{}).SavePlan(nil)
+	require.NotNil(t, err)
+}
+
+func Test_LoadPlanUnsupportedVersion(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.Nil(t, os.WriteFile(path, []byte(`{"version": 2}`), 0600))
+
+	_, err := LoadPlan(path)
+	require.NotNil(t, err)
+}
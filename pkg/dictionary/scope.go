@@ -0,0 +1,104 @@
+package dictionary
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+)
+
+// keyScope restricts a sync to the keys owned by a single writer of a shared dictionary
+type keyScope struct {
+	scope string
+}
+
+// WithKeyScope allows restricting a sync to the keys inside a scope so many writers can share a dictionary.
+// The scope is a key prefix or, if it contains any of *?[, a glob pattern matching the whole key
+// in which * also matches /.
+// Remote items outside the scope are never diffed, deleted or reverted and local items outside
+// the scope are rejected. An empty scope includes every key.
+func WithKeyScope(scope string) option {
+	return func(m *manager) {
+		m.scope = keyScope{scope: scope}
+	}
+}
+
+func (s keyScope) isPattern() bool {
+	return strings.ContainsAny(s.scope, `*?[\`)
+}
+
+// validate returns an error if the scope is an invalid glob pattern
+func (s keyScope) validate() error {
+
+	if !s.isPattern() {
+		return nil
+	}
+
+	if _, err := matchKey(s.scope, ""); err != nil {
+		return errors.Wrapf(err, "invalid key scope %s", s.scope)
+	}
+	return nil
+}
+
+// allows returns true if the key is inside the scope
+func (s keyScope) allows(key string) bool {
+
+	if s.isPattern() {
+		// the pattern has already been validated
+		matched, _ := matchKey(s.scope, key)
+		return matched
+	}
+	return strings.HasPrefix(key, s.scope)
+}
+
+// check returns ErrKeysOutOfScope listing every local item outside the scope
+func (s keyScope) check(records [][]string) error {
+
+	outside := []string{}
+
+	for i := range records {
+		if len(records[i]) > 0 && !s.allows(records[i][0]) {
+			outside = append(outside, records[i][0])
+		}
+	}
+
+	if len(outside) > 0 {
+		return &ErrKeysOutOfScope{Scope: s.scope, Keys: outside}
+	}
+	return nil
+}
+
+func (s keyScope) items(items []*fastly.DictionaryItem) []*fastly.DictionaryItem {
+
+	scoped := make([]*fastly.DictionaryItem, 0, len(items))
+
+	for i := range items {
+		if s.allows(items[i].ItemKey) {
+			scoped = append(scoped, items[i])
+		}
+	}
+	return scoped
+}
+
+func (s keyScope) restrict(items map[string]string) map[string]string {
+
+	scoped := map[string]string{}
+
+	for k, v := range items {
+		if s.allows(k) {
+			scoped[k] = v
+		}
+	}
+	return scoped
+}
+
+// ErrKeysOutOfScope signals local items are outside of the key scope
+type ErrKeysOutOfScope struct {
+	Scope string
+	Keys  []string
+}
+
+func (e *ErrKeysOutOfScope) Error() string {
+	return fmt.Sprintf("%d keys outside of scope %s : %s", len(e.Keys), e.Scope, strings.Join(e.Keys, ", "))
+}
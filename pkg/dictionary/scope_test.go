@@ -0,0 +1,122 @@
+package dictionary
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func localItems(records ...[]string) *mockLocalReader {
+	return &mockLocalReader{
+		reader: func() ([][]string, error) {
+			return records, nil
+		},
+	}
+}
+
+func Test_SyncWithKeyScope(t *testing.T) {
+	testCases := []struct {
+		name     string
+		scope    string
+		local    [][]string
+		expected map[string]string
+	}{
+		{
+			name:  "no scope",
+			local: [][]string{{"team-a/one", "new"}},
+			expected: map[string]string{
+				"team-a/one": "new",
+			},
+		},
+		{
+			name:  "prefix",
+			scope: "team-a/",
+			local: [][]string{{"team-a/one", "new"}},
+			expected: map[string]string{
+				"team-a/one": "new", "team-b/one": "b", "shared": "s",
+			},
+		},
+		{
+			name:  "pattern",
+			scope: "team-?/one",
+			local: [][]string{{"team-a/one", "new"}},
+			expected: map[string]string{
+				"team-a/one": "new", "team-a/two": "a", "shared": "s",
+			},
+		},
+		{
+			name:  "pattern matching nested keys",
+			scope: "team-a/*",
+			local: [][]string{{"team-a/nested/one", "new"}},
+			expected: map[string]string{
+				"team-a/nested/one": "new", "team-b/one": "b", "shared": "s",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			remote := &mockRemoteDictionary{
+				items: map[string]string{"team-a/one": "a", "team-a/two": "a", "team-b/one": "b", "shared": "s"},
+			}
+
+			m := Manager(remote, WithLocalReader(localItems(tc.local...)), WithKeyScope(tc.scope))
+
+			_, err := m.Sync()
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, remote.items)
+		})
+	}
+}
+
+func Test_KeyScopeRejectsLocalKeys(t *testing.T) {
+
+	remote := &mockRemoteDictionary{items: map[string]string{"team-b/one": "b"}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"team-a/one", "a"}, []string{"team-b/one", "mine"})),
+		WithKeyScope("team-a/"),
+	)
+
+	_, err := m.Plan()
+
+	var outside *ErrKeysOutOfScope
+	require.True(t, errors.As(err, &outside))
+	require.Equal(t, []string{"team-b/one"}, outside.Keys)
+	require.Equal(t, 0, remote.batches)
+}
+
+func Test_KeyScopeInvalidPattern(t *testing.T) {
+
+	m := Manager(&mockRemoteDictionary{}, WithLocalReader(localItems()), WithKeyScope("team-[a"))
+
+	_, err := m.Plan()
+	require.NotNil(t, err)
+}
+
+func Test_KeyScopeRevert(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	remote := &mockRemoteDictionary{items: map[string]string{"team-a/one": "a", "team-b/one": "b"}}
+
+	m := Manager(remote,
+		WithLocalReader(localItems([]string{"team-a/one", "new"})),
+		WithKeyScope("team-a/"),
+		WithJournal(path),
+	)
+
+	_, err := m.Sync()
+	require.Nil(t, err)
+
+	// another pipeline writes inside its own scope after the sync
+	remote.items["team-b/one"] = "b2"
+	remote.items["team-b/two"] = "b2"
+
+	m = Manager(remote, WithKeyScope("team-a/"), WithJournal(path))
+	require.Nil(t, m.Revert())
+	require.Equal(t, map[string]string{"team-a/one": "a", "team-b/one": "b2", "team-b/two": "b2"}, remote.items)
+}
@@ -0,0 +1,141 @@
+package dictionary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Redacted replaces the values of items containing substituted secrets
+	Redacted = "<redacted>"
+
+	filePlaceholderPrefix = "file:"
+)
+
+// matches ${ENV_VAR} and ${file:/path/to/secret}
+var placeholder = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// WithSubstitution allows placeholders in local values to be expanded before they are validated.
+// ${NAME} is replaced by the value of lookupEnv(NAME) and ${file:/path} by the contents of the file
+// without any trailing newline. Items containing placeholders are marked as Sensitive.
+// The journal and last sync save the placeholders, and a hash of the substituted value,
+// rather than the secrets so resuming or reverting a sync substitutes them again.
+// A nil lookupEnv disables substitution.
+func WithSubstitution(lookupEnv func(string) (string, bool)) option {
+	return func(m *manager) {
+		m.lookupEnv = lookupEnv
+	}
+}
+
+// substitute expands the placeholders in the local values recording the value
+// each item with placeholders was substituted from as its template
+func (m *manager) substitute(records [][]string) error {
+
+	m.templates = map[string]string{}
+
+	if m.lookupEnv == nil {
+		return nil
+	}
+
+	for i := range records {
+
+		if len(records[i]) < 2 || !placeholder.MatchString(records[i][1]) {
+			continue
+		}
+
+		key, template := records[i][0], records[i][1]
+		value, err := m.expand(key, template)
+
+		if err != nil {
+			return err
+		}
+
+		records[i][1] = value
+		m.templates[key] = template
+	}
+
+	return nil
+}
+
+// expand replaces the placeholders in the template of an item or returns ErrMissingSecret
+func (m *manager) expand(key, template string) (string, error) {
+
+	if m.lookupEnv == nil {
+		return "", fmt.Errorf("substitution is required to expand the secret value of %s", key)
+	}
+
+	var missing error
+
+	value := placeholder.ReplaceAllStringFunc(template, func(match string) string {
+
+		name := placeholder.FindStringSubmatch(match)[1]
+		value, err := m.resolvePlaceholder(name)
+
+		if err != nil && missing == nil {
+			missing = &ErrMissingSecret{Key: key, Placeholder: match, Err: err}
+		}
+		return value
+	})
+
+	return value, missing
+}
+
+// secretHash identifies a substituted value without saving it
+func secretHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (m *manager) resolvePlaceholder(name string) (string, error) {
+
+	if strings.HasPrefix(name, filePlaceholderPrefix) {
+
+		b, err := os.ReadFile(strings.TrimPrefix(name, filePlaceholderPrefix))
+
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	value, found := m.lookupEnv(name)
+
+	if !found {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// Redact returns the change with its values replaced if they are Sensitive or,
+// for the remote value, RemoteSensitive
+func (c Change) Redact() Change {
+
+	if c.From != "" && (c.Sensitive || c.RemoteSensitive) {
+		c.From = Redacted
+	}
+
+	if c.To != "" && c.Sensitive {
+		c.To = Redacted
+	}
+	return c
+}
+
+// ErrSealedSecret signals a secret value was saved without the template to substitute it from
+var ErrSealedSecret = errors.New("secret value was saved without its placeholder")
+
+// ErrMissingSecret signals a placeholder in a local value could not be resolved
+type ErrMissingSecret struct {
+	Key         string
+	Placeholder string
+	Err         error
+}
+
+func (e *ErrMissingSecret) Error() string {
+	return fmt.Sprintf("cannot substitute %s for key %s : %s", e.Placeholder, e.Key, e.Err)
+}
@@ -0,0 +1,198 @@
+package dictionary
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Substitution(t *testing.T) {
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.Nil(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0600))
+
+	env := func(key string) (string, bool) {
+		if key == "API_KEY" {
+			return "env-secret", true
+		}
+		return "", false
+	}
+
+	testCases := []struct {
+		name     string
+		local    [][]string
+		env      func(string) (string, bool)
+		expected []Change
+		err      bool
+	}{
+		{
+			name:  "environment and file placeholders",
+			local: [][]string{{"one-key", "key=${API_KEY}"}, {"two-key", "${file:" + secretFile + "}"}, {"three-key", "plain"}},
+			env:   env,
+			expected: []Change{
+				{Operation: fastly.CreateBatchOperation, Key: "one-key", To: "key=env-secret", Sensitive: true},
+				{Operation: fastly.CreateBatchOperation, Key: "three-key", To: "plain"},
+				{Operation: fastly.CreateBatchOperation, Key: "two-key", To: "file-secret", Sensitive: true},
+			},
+		},
+		{
+			name:  "substitution disabled",
+			local: [][]string{{"one-key", "${API_KEY}"}},
+			expected: []Change{
+				{Operation: fastly.CreateBatchOperation, Key: "one-key", To: "${API_KEY}"},
+			},
+		},
+		{
+			name:  "missing environment variable",
+			local: [][]string{{"one-key", "${MISSING}"}},
+			env:   env,
+			err:   true,
+		},
+		{
+			name:  "missing file",
+			local: [][]string{{"one-key", "${file:/does/not/exist}"}},
+			env:   env,
+			err:   true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+
+			local := &mockLocalReader{
+				reader: func() ([][]string, error) {
+					return tc.local, nil
+				},
+			}
+
+			m := Manager(&mockRemoteDictionary{}, WithLocalReader(local), WithSubstitution(tc.env))
+
+			changes, err := m.Plan()
+
+			if tc.err {
+				var missing *ErrMissingSecret
+				require.True(t, errors.As(err, &missing))
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, changes)
+		})
+	}
+}
+
+func Test_Redact(t *testing.T) {
+
+	c := Change{Operation: fastly.UpdateBatchOperation, Key: "one-key", From: "old", To: "new", Sensitive: true}
+	require.Equal(t, Change{Operation: fastly.UpdateBatchOperation, Key: "one-key", From: Redacted, To: Redacted, Sensitive: true}, c.Redact())
+
+	c = Change{Operation: fastly.CreateBatchOperation, Key: "one-key", To: "new"}
+	require.Equal(t, c, c.Redact())
+}
+
+func Test_ValueTooLongIsNotPrinted(t *testing.T) {
+
+	secret := strings.Repeat("s", maxValueLength+1)
+	env := func(string) (string, bool) { return secret, true }
+
+	m := Manager(&mockRemoteDictionary{}, WithLocalReader(localItems([]string{"one-key", "${API_KEY}"})), WithSubstitution(env))
+
+	_, err := m.Plan()
+
+	var tooLong *ErrValueTooLong
+	require.True(t, errors.As(err, &tooLong))
+	require.Equal(t, &ErrValueTooLong{Key: "one-key", Length: maxValueLength + 1}, tooLong)
+	require.NotContains(t, err.Error(), secret)
+}
+
+func Test_PreviousSecretsAreRedacted(t *testing.T) {
+
+	env := func(string) (string, bool) { return "env-secret", true }
+
+	dir := t.TempDir()
+	journal, last := filepath.Join(dir, "journal.json"), filepath.Join(dir, "last-sync.json")
+	remote := &mockRemoteDictionary{items: map[string]string{}}
+
+	m := Manager(remote, WithLocalReader(localItems([]string{"one-key", "${API_KEY}"}, []string{"two-key", "${API_KEY}"})),
+		WithSubstitution(env), WithJournal(journal), WithLastSync(last))
+	_, err := m.Sync()
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"one-key": "env-secret", "two-key": "env-secret"}, remote.items)
+
+	// the secrets are saved as their placeholders
+	for _, path := range []string{journal, last} {
+		b, err := os.ReadFile(path)
+		require.Nil(t, err)
+		require.NotContains(t, string(b), "env-secret")
+		require.Contains(t, string(b), "${API_KEY}")
+	}
+
+	// replacing or deleting a secret does not print it
+	m = Manager(remote, WithLocalReader(localItems([]string{"one-key", "plain"})), WithSubstitution(env), WithLastSync(last))
+	changes, err := m.Plan()
+	require.Nil(t, err)
+	require.Len(t, changes, 2)
+
+	for _, c := range changes {
+		require.Equal(t, Redacted, c.Redact().From)
+	}
+	require.Equal(t, "plain", changes[0].Redact().To)
+
+	// the secrets replaced by a sync are journaled as their placeholders and restored by a revert
+	m = Manager(remote, WithLocalReader(localItems([]string{"one-key", "plain"})), WithSubstitution(env), WithJournal(journal), WithLastSync(last))
+	_, err = m.Sync()
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"one-key": "plain"}, remote.items)
+
+	b, err := os.ReadFile(journal)
+	require.Nil(t, err)
+	require.NotContains(t, string(b), "env-secret")
+
+	require.Nil(t, Manager(remote, WithSubstitution(env), WithJournal(journal), WithLastSync(last)).Revert())
+	require.Equal(t, map[string]string{"one-key": "env-secret", "two-key": "env-secret"}, remote.items)
+
+	b, err = os.ReadFile(last)
+	require.Nil(t, err)
+	require.NotContains(t, string(b), "env-secret")
+}
+
+func Test_ResumeSubstitutesSecrets(t *testing.T) {
+
+	env := func(string) (string, bool) { return "env-secret", true }
+
+	records := [][]string{}
+	for i := 0; i < 1500; i++ {
+		records = append(records, []string{fmt.Sprintf("key-%04d", i), "${API_KEY}"})
+	}
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	remote := &mockRemoteDictionary{items: map[string]string{}, failOn: 2}
+
+	// the second of two batches fails
+	_, err := Manager(remote, WithLocalReader(localItems(records...)), WithSubstitution(env), WithJournal(path)).Sync()
+	require.NotNil(t, err)
+	require.Equal(t, 1000, len(remote.items))
+
+	b, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.NotContains(t, string(b), "env-secret")
+
+	// the secrets cannot be resumed without substitution
+	err = Manager(remote, WithJournal(path)).Resume()
+	require.NotNil(t, err)
+	require.Equal(t, 1000, len(remote.items))
+
+	require.Nil(t, Manager(remote, WithJournal(path), WithSubstitution(env)).Resume())
+	require.Equal(t, 1500, len(remote.items))
+
+	for _, v := range remote.items {
+		require.Equal(t, "env-secret", v)
+	}
+}
@@ -0,0 +1,52 @@
+package dictionary
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/fastly/go-fastly/fastly"
+	"gopkg.in/yaml.v3"
+)
+
+// Export writes remote dictionary items sorted by key in a format that can be read back by NewReader
+func Export(w io.Writer, fileType FileType, items []*fastly.DictionaryItem) error {
+
+	sorted := make([]*fastly.DictionaryItem, len(items))
+	copy(sorted, items)
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ItemKey < sorted[j].ItemKey })
+
+	switch fileType {
+	case CSV:
+		writer := csv.NewWriter(w)
+
+		for _, item := range sorted {
+			if err := writer.Write([]string{item.ItemKey, item.ItemValue}); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		// map keys are encoded in sorted order
+		return encoder.Encode(fastlyDictionaryItemsToMap(sorted))
+
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(fastlyDictionaryItemsToMap(sorted)); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	return fmt.Errorf("unsupported export file type : %s", fileType)
+}
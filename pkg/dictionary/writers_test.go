@@ -0,0 +1,49 @@
+package dictionary
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fastly/go-fastly/fastly"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExportRoundTrips(t *testing.T) {
+
+	items := []*fastly.DictionaryItem{
+		&fastly.DictionaryItem{ItemKey: "two-key", ItemValue: "two, \"value\""},
+		&fastly.DictionaryItem{ItemKey: "one-key", ItemValue: "one-value"},
+		&fastly.DictionaryItem{ItemKey: "flag", ItemValue: "true"},
+		&fastly.DictionaryItem{ItemKey: "number", ItemValue: "10"},
+		&fastly.DictionaryItem{ItemKey: "empty", ItemValue: ""},
+	}
+
+	expected := [][]string{
+		{"empty", ""},
+		{"flag", "true"},
+		{"number", "10"},
+		{"one-key", "one-value"},
+		{"two-key", "two, \"value\""},
+	}
+
+	for _, ft := range []FileType{CSV, JSON, YAML} {
+		t.Run(string(ft), func(t *testing.T) {
+
+			buf := &bytes.Buffer{}
+			err := Export(buf, ft, items)
+			require.Nil(t, err)
+
+			reader, err := NewReader(ft, buf)
+			require.Nil(t, err)
+
+			records, err := reader.ReadAll()
+			require.Nil(t, err)
+			require.Equal(t, expected, records)
+		})
+	}
+}
+
+func Test_ExportUnsupportedType(t *testing.T) {
+	err := Export(&bytes.Buffer{}, DotEnv, nil)
+	require.NotNil(t, err)
+}
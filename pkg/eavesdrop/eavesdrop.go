@@ -9,6 +9,7 @@ import (
 	"os/user"
 
 	"github.com/fastly/go-fastly/fastly"
+	"github.com/mdevilliers/fastly-cli/pkg/audit"
 	"github.com/mdevilliers/fastly-cli/pkg/builder"
 	"github.com/pkg/errors"
 )
@@ -36,12 +37,20 @@ func WithLocalBinding(endpoint string, port int) option { // nolint
 	}
 }
 
+// WithAudit adds the facility to record starting and disposing of sessions to an audit log
+func WithAudit(a audit.Auditor) option { // nolint
+	return func(r *sessionOptions) {
+		r.Audit = a
+	}
+}
+
 type sessionOptions struct {
 	ExternalEndpoint string
 	ExternalPort     int
 	LocalEndpoint    string
 	LocalPort        int
 	Service          *fastly.Service
+	Audit            audit.Auditor
 }
 
 // NewSession returns a connction to an existing service
@@ -73,13 +82,15 @@ func (s *session) Dispose(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "error getting latest service")
 	}
-	instance := builder.New(s.client, latest.ID, latest.ActiveVersion.Number)
-	return instance.Apply(s.ensurePreviousSessionDoesNotExist)
+	instance := builder.New(s.client, latest.ID, latest.ActiveVersion.Number, builder.WithAudit(s.Audit))
+	err = instance.Apply(s.ensurePreviousSessionDoesNotExist)
+
+	return s.record(audit.EavesdropDispose, err)
 }
 
 func (s *session) StartListening() error {
 
-	instance := builder.New(s.client, s.Service.ID, int(s.Service.ActiveVersion))
+	instance := builder.New(s.client, s.Service.ID, int(s.Service.ActiveVersion), builder.WithAudit(s.Audit))
 
 	createSyslog := func(current builder.ServiceInfo) error {
 
@@ -99,7 +110,7 @@ func (s *session) StartListening() error {
 		return nil
 	}
 
-	err := instance.Apply(s.ensurePreviousSessionDoesNotExist, createSyslog)
+	err := s.record(audit.EavesdropStart, instance.Apply(s.ensurePreviousSessionDoesNotExist, createSyslog))
 
 	if err != nil {
 		return err
@@ -118,6 +129,12 @@ func (s *session) StartListening() error {
 	return nil
 }
 
+// record writes the outcome of starting or disposing of the session to the audit log
+// returning the outcome or, if the audit log cannot be written, that error
+func (s *session) record(action string, err error) error {
+	return audit.Outcome(s.Audit, audit.Record{Action: action, ServiceID: s.Service.ID}, err)
+}
+
 func listen(listener net.Listener) {
 
 	defer listener.Close() // nolint:errcheck
@@ -2,6 +2,7 @@ package terminal
 
 import (
 	"github.com/manifoldco/promptui"
+	"github.com/pkg/errors"
 )
 
 // TextGatherer displays a prompt and harvests textual input
@@ -29,3 +30,22 @@ func GetInputSecret() TextGatherer {
 		return prompt.Run()
 	}
 }
+
+// Confirm displays a yes/no prompt returning true if the user answered yes
+func Confirm(label string) (bool, error) {
+
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+
+	_, err := prompt.Run()
+
+	if err != nil {
+		if errors.Is(err, promptui.ErrAbort) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}